@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/database/postgresql"
+	pgrepository "github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/repository/postgresql"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/apikey"
+	"go.uber.org/zap"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// projectionInterval is how often projection workers poll for new events.
+const projectionInterval = time.Second
+
+// runCommand runs a maintenance subcommand instead of the server, e.g.
+// `orders rebuild-projections [name...]`.
+func runCommand(pgDbContext *postgresql.PostgresContext, name string, args []string) error {
+	switch name {
+	case "rebuild-projections":
+		return rebuildProjections(pgDbContext, args)
+	case "api-keys":
+		return manageAPIKeys(pgDbContext, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+func projectionWorkers(pgDbContext *postgresql.PostgresContext) []*pgrepository.ProjectionWorker {
+	return []*pgrepository.ProjectionWorker{
+		pgrepository.NewProjectionWorker(pgDbContext.Pool, pgrepository.NewOrderProjection(), projectionInterval),
+		pgrepository.NewProjectionWorker(pgDbContext.Pool, pgrepository.NewUserOrderSummaryProjection(), projectionInterval),
+		pgrepository.NewProjectionWorker(pgDbContext.Pool, pgrepository.NewSalesProjection(), projectionInterval),
+	}
+}
+
+// rebuildProjections replays the event log into the named projections, or
+// into all of them when no names are given.
+func rebuildProjections(pgDbContext *postgresql.PostgresContext, names []string) error {
+	workers := projectionWorkers(pgDbContext)
+	if len(names) > 0 {
+		selected := make([]*pgrepository.ProjectionWorker, 0, len(names))
+		for _, name := range names {
+			i := slices.IndexFunc(workers, func(w *pgrepository.ProjectionWorker) bool { return w.Name() == name })
+			if i < 0 {
+				return fmt.Errorf("unknown projection %q", name)
+			}
+			selected = append(selected, workers[i])
+		}
+		workers = selected
+	}
+
+	ctx := context.Background()
+	for _, worker := range workers {
+		start := time.Now()
+		events, err := worker.Rebuild(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("Rebuilt projection",
+			zap.String("projection", worker.Name()),
+			zap.Int("events", events),
+			zap.Duration("took", time.Since(start)),
+		)
+	}
+	return nil
+}
+
+// manageAPIKeys runs `api-keys create|list|rotate|revoke`. Operators on the
+// host are trusted, so the command acts with the api_keys:manage permission;
+// this is how the first key is issued before anyone can call the admin API.
+func manageAPIKeys(pgDbContext *postgresql.PostgresContext, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: api-keys create|list|rotate|revoke")
+	}
+
+	uc := apikey.NewManageAPIKeysUseCase(pgrepository.NewAPIKeyRepository(pgDbContext.Pool))
+	ctx := identity.WithIdentity(context.Background(), &identity.Identity{
+		Subject:     "cli",
+		Permissions: []string{string(authz.APIKeysManage)},
+	})
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("api-keys create", flag.ContinueOnError)
+		name := flags.String("name", "", "name of the calling service")
+		scopes := flags.String("scopes", "", "comma separated permissions granted to the key")
+		expires := flags.Duration("expires", 0, "lifetime of the key, e.g. 2160h; zero never expires")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		input := apikey.CreateAPIKeyInput{Name: *name}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				input.Scopes = append(input.Scopes, scope)
+			}
+		}
+		if *expires > 0 {
+			expiresAt := time.Now().Add(*expires)
+			input.ExpiresAt = &expiresAt
+		}
+
+		issued, err := uc.Create(ctx, input)
+		if err != nil {
+			return err
+		}
+		return printJSON(issued)
+	case "list":
+		keys, err := uc.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printJSON(keys)
+	case "rotate":
+		flags := flag.NewFlagSet("api-keys rotate", flag.ContinueOnError)
+		grace := flags.Duration("grace", 0, "how long the old key keeps working, e.g. 24h")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		keyID, err := apiKeyIDArg(flags.Args())
+		if err != nil {
+			return err
+		}
+
+		issued, err := uc.Rotate(ctx, keyID, *grace)
+		if err != nil {
+			return err
+		}
+		return printJSON(issued)
+	case "revoke":
+		keyID, err := apiKeyIDArg(args[1:])
+		if err != nil {
+			return err
+		}
+		return uc.Revoke(ctx, keyID)
+	default:
+		return fmt.Errorf("unknown api-keys command %q", args[0])
+	}
+}
+
+func apiKeyIDArg(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected exactly one API key id")
+	}
+	keyID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid API key id %q", args[0])
+	}
+	return keyID, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
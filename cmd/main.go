@@ -3,11 +3,31 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/handler"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/router"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/auth"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/config"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/database/postgresql"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/payment"
+	pgrepository "github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/repository/postgresql"
+	shippingrates "github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/shipping"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/tax"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/health"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/metrics"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/ratelimit"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/apikey"
+	usecase "github.com/Sinet2000/Martix-Orders-Go/internal/usecase/order"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/promotion"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/reporting"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/returns"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/saga"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/shipping"
+	"github.com/Sinet2000/Martix-Orders-Go/sql/migrations"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"log"
 	"net/http"
@@ -15,6 +35,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Reports accept IANA timezones and the runtime image has no zoneinfo
+	_ "time/tzdata"
 )
 
 func main() {
@@ -31,6 +53,23 @@ func main() {
 	// Ensure we flush any buffered log entries on shutdown
 	defer logger.Sync()
 
+	tracingConfig, err := tracing.LoadConfig(cfg.TracingPath)
+	if err != nil {
+		logger.Fatal("Failed to load tracing config", zap.Error(err))
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		logger.Fatal("Failed to set up tracing", zap.Error(err))
+	}
+	// Export the spans still buffered on shutdown
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// 3. Log application startup
 	logger.Info("Starting application",
 		zap.String("environment", cfg.AppEnv),
@@ -43,27 +82,167 @@ func main() {
 	}
 	defer pgDbContext.Close()
 
+	if len(os.Args) > 1 {
+		if err := runMigrations(context.Background(), pgDbContext); err != nil {
+			logger.Fatal("Failed to apply database migrations", zap.Error(err))
+		}
+		if err := runCommand(pgDbContext, os.Args[1], os.Args[2:]); err != nil {
+			logger.Fatal("Command failed", zap.String("command", os.Args[1]), zap.Error(err))
+		}
+		return
+	}
+
 	//mongoDbContext, err := mongodb.NewMongoService(&cfg.MongoDB)
 	//if err != nil {
 	//	logger.Fatal("Failed to initialize MongoDB", zap.Error(err))
 	//}
 	//defer mongoDbContext.Close(context.Background())
 
+	healthChecker := health.NewChecker(cfg.ContextTimeout)
+	healthChecker.Add("postgresql", pgDbContext.Ping)
+	//healthChecker.Add("mongodb", mongoDbContext.Ping)
+
+	if err := metrics.RegisterPgxPool(pgDbContext.Pool); err != nil {
+		logger.Fatal("Failed to register PostgreSQL pool metrics", zap.Error(err))
+	}
+
 	// Initialize Gin
 	gin.SetMode(gin.DebugMode)
 
-	//orderRepo := repository.NewOrderRepository(db)
-	//
-	//// Initialize use case
-	//createOrderUseCase := usecase.NewCreateOrderUseCase(orderRepo)
-	//
-	//// Initialize handlers
-	//orderHandler := handler.NewOrderHandler(createOrderUseCase, logger)
-	//
-	//// Setup router
-	router := router.SetupRouter()
+	orderRepo := pgrepository.NewOrderRepository(pgDbContext.Pool)
+	productRepo := pgrepository.NewProductRepository(pgDbContext.Pool)
+	couponRepo := pgrepository.NewCouponRepository(pgDbContext.Pool)
+	shipmentRepo := pgrepository.NewShipmentRepository(pgDbContext.Pool)
+	returnRepo := pgrepository.NewReturnRepository(pgDbContext.Pool)
+	refundRepo := pgrepository.NewRefundRepository(pgDbContext.Pool)
+	paymentRepo := pgrepository.NewPaymentRepository(pgDbContext.Pool)
+	inventoryRepo := pgrepository.NewInventoryRepository(pgDbContext.Pool)
+	sagaRepo := pgrepository.NewSagaRepository(pgDbContext.Pool)
+	readModelRepo := pgrepository.NewReadModelRepository(pgDbContext.Pool)
+	analyticsRepo := pgrepository.NewAnalyticsRepository(pgDbContext.Pool)
+	apiKeyRepo := pgrepository.NewAPIKeyRepository(pgDbContext.Pool)
+
+	err = metrics.RegisterProjectionLag(cfg.ContextTimeout, func(ctx context.Context) ([]metrics.ProjectionLag, error) {
+		lags, err := readModelRepo.ProjectionLag(ctx)
+		if err != nil {
+			return nil, err
+		}
+		gauges := make([]metrics.ProjectionLag, len(lags))
+		for i, lag := range lags {
+			gauges[i] = metrics.ProjectionLag{
+				Projection:    lag.Projection,
+				EventsBehind:  lag.EventsBehind,
+				SecondsBehind: lag.SecondsBehind,
+			}
+		}
+		return gauges, nil
+	})
+	if err != nil {
+		logger.Fatal("Failed to register projection lag metrics", zap.Error(err))
+	}
+
+	// Initialize domain services
+	taxRates, err := tax.LoadRateTable(cfg.TaxRatesPath)
+	if err != nil {
+		logger.Fatal("Failed to load tax rates", zap.Error(err))
+	}
+	taxCalculator, err := tax.NewTableCalculator(taxRates)
+	if err != nil {
+		logger.Fatal("Invalid tax rates", zap.Error(err))
+	}
+	shippingRates, err := shippingrates.LoadRateRules(cfg.ShippingRatesPath)
+	if err != nil {
+		logger.Fatal("Failed to load shipping rates", zap.Error(err))
+	}
+	shippingProvider, err := shippingrates.NewRuleProvider(shippingRates)
+	if err != nil {
+		logger.Fatal("Invalid shipping rates", zap.Error(err))
+	}
+	paymentConfig, err := payment.LoadGatewayConfig(cfg.PaymentConfigPath)
+	if err != nil {
+		logger.Fatal("Failed to load payment gateway config", zap.Error(err))
+	}
+	paymentGateway, err := payment.NewGateway(paymentConfig)
+	if err != nil {
+		logger.Fatal("Invalid payment gateway config", zap.Error(err))
+	}
+	authConfig, err := auth.LoadConfig(cfg.AuthConfigPath)
+	if err != nil {
+		logger.Fatal("Failed to load auth config", zap.Error(err))
+	}
+	tokenVerifier, err := auth.NewVerifier(authConfig, cfg.JWTSecret)
+	if err != nil {
+		logger.Fatal("Invalid auth config", zap.Error(err))
+	}
+	rbacPolicy, err := authz.LoadPolicy(cfg.RBACPolicyPath)
+	if err != nil {
+		logger.Fatal("Failed to load authorization policy", zap.Error(err))
+	}
+	rateLimitConfig, err := ratelimit.LoadConfig(cfg.RateLimitPath)
+	if err != nil {
+		logger.Fatal("Failed to load rate limit config", zap.Error(err))
+	}
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if rateLimitConfig.Store == ratelimit.StoreRedis {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer redisClient.Close()
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+	}
+	rateLimiter := ratelimit.NewLimiter(rateLimitConfig, rateLimitStore)
+
+	// Initialize use cases
+	createOrderUseCase := usecase.NewCreateOrderUseCase(
+		orderRepo, productRepo, couponRepo, taxCalculator, shippingProvider,
+	)
+	searchOrdersUseCase := usecase.NewSearchOrdersUseCase(orderRepo)
+	customerOrdersUseCase := usecase.NewCustomerOrdersUseCase(orderRepo)
+	createShipmentUseCase := usecase.NewCreateShipmentUseCase(shipmentRepo)
+	confirmDeliveryUseCase := usecase.NewConfirmDeliveryUseCase(shipmentRepo)
+	listShipmentsUseCase := usecase.NewListShipmentsUseCase(shipmentRepo)
+	sagaOrchestrator := saga.NewOrchestrator(sagaRepo)
+	checkoutUseCase := usecase.NewCheckoutUseCase(
+		orderRepo, paymentRepo, inventoryRepo, paymentGateway, sagaOrchestrator, cfg.ContextTimeout,
+	)
+	manageCouponsUseCase := promotion.NewManageCouponsUseCase(couponRepo)
+	quoteShippingUseCase := shipping.NewQuoteShippingUseCase(productRepo, shippingProvider)
+	requestReturnUseCase := returns.NewRequestReturnUseCase(orderRepo, returnRepo)
+	reviewReturnUseCase := returns.NewReviewReturnUseCase(returnRepo)
+	issueRefundUseCase := returns.NewIssueRefundUseCase(orderRepo, returnRepo, refundRepo, paymentRepo, paymentGateway)
+	listReturnsUseCase := returns.NewListReturnsUseCase(orderRepo, returnRepo, refundRepo)
+	listUserOrdersUseCase := reporting.NewListUserOrdersUseCase(readModelRepo)
+	salesReportUseCase := reporting.NewSalesReportUseCase(readModelRepo, readModelRepo)
+	salesAnalyticsUseCase := reporting.NewSalesAnalyticsUseCase(analyticsRepo)
+	manageAPIKeysUseCase := apikey.NewManageAPIKeysUseCase(apiKeyRepo)
+	authenticateAPIKeyUseCase := apikey.NewAuthenticateAPIKeyUseCase(apiKeyRepo)
+
+	// Initialize handlers
+	orderHandler := handler.NewOrderHandler(createOrderUseCase, searchOrdersUseCase)
+	couponHandler := handler.NewCouponHandler(manageCouponsUseCase)
+	shippingHandler := handler.NewShippingHandler(quoteShippingUseCase)
+	shipmentHandler := handler.NewShipmentHandler(createShipmentUseCase, confirmDeliveryUseCase, listShipmentsUseCase)
+	paymentHandler := handler.NewPaymentHandler(checkoutUseCase)
+	returnHandler := handler.NewReturnHandler(
+		requestReturnUseCase, reviewReturnUseCase, issueRefundUseCase, listReturnsUseCase,
+	)
+	reportHandler := handler.NewReportHandler(listUserOrdersUseCase, salesReportUseCase, salesAnalyticsUseCase)
+	customerOrderHandler := handler.NewCustomerOrderHandler(customerOrdersUseCase)
+	apiKeyHandler := handler.NewAPIKeyHandler(manageAPIKeysUseCase)
+	logLevelHandler := handler.NewLogLevelHandler()
+	healthHandler := handler.NewHealthHandler(healthChecker)
 
-	// TODO: Add routes here
+	// Setup router
+	router, err := router.SetupRouter(
+		cfg.TrustedProxies, tokenVerifier, authenticateAPIKeyUseCase, rbacPolicy, rateLimiter, healthChecker,
+		orderHandler, couponHandler, shippingHandler, shipmentHandler, returnHandler, paymentHandler, reportHandler,
+		customerOrderHandler, apiKeyHandler, logLevelHandler, healthHandler,
+	)
+	if err != nil {
+		logger.Fatal("Failed to set up router", zap.Error(err))
+	}
 
 	// Create server
 	srv := &http.Server{
@@ -71,27 +250,123 @@ func main() {
 		Handler: router,
 	}
 
+	// Prometheus scrape endpoint on its own address, which is not exposed
+	// like the API
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", metrics.Handler())
+	metricsSrv := &http.Server{
+		Addr:    cfg.MetricsAddress,
+		Handler: metricsMux,
+	}
+
+	// Handle SIGTERM from here on, so that a shutdown during the migrations
+	// rolls them back instead of killing the process
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+
 	// Graceful shutdown
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
+	go func() {
+		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}()
+
+	// The server already answers the probes; /startupz and /readyz report
+	// starting and /api answers 503 until the migrations are applied
+	migrateCtx, stopMigrating := context.WithCancel(context.Background())
+	migrated := make(chan error, 1)
+	go func() {
+		migrated <- runMigrations(migrateCtx, pgDbContext)
+	}()
+
+	select {
+	case err := <-migrated:
+		stopMigrating()
+		if err != nil {
+			logger.Fatal("Failed to apply database migrations", zap.Error(err))
+		}
+	case <-shutdown:
+		logger.Info("Shutting down application before the migrations are applied...")
+		stopMigrating()
+		if err := <-migrated; err != nil {
+			logger.Warn("Stopped applying database migrations", zap.Error(err))
+		}
+		shutdownServers(srv, metricsSrv)
+		return
+	}
+	healthChecker.MarkStarted()
+
+	// Keep the read models up to date with the order events
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	for _, worker := range projectionWorkers(pgDbContext) {
+		go worker.Run(workerCtx)
+	}
+
+	// Finish sagas interrupted by a previous crash. Sagas saved within the
+	// grace period may still be running in another instance.
+	go func() {
+		if err := sagaOrchestrator.Resume(context.Background(), 3*cfg.ContextTimeout); err != nil {
+			logger.Error("Failed to resume sagas", zap.Error(err))
+		}
+	}()
+
+	// Reapply the log levels of the config file on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := logger.ReloadLevels(); err != nil {
+				logger.Error("Failed to reload log levels", zap.Error(err))
+				continue
+			}
+			logger.Info("Reloaded log levels", zap.Any("levels", logger.GetLevels()))
+		}
+	}()
 
 	// Wait for interrupt signal
 	// 5. Handle graceful shutdown
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
-
 	<-shutdown
 	logger.Info("Shutting down application...")
 
+	// Let load balancers see /readyz fail before new connections are refused
+	healthChecker.MarkDraining()
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownServers(srv, metricsSrv)
+}
+
+func shutdownServers(servers ...*http.Server) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown:", zap.Error(err))
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Fatal("Server forced to shutdown:", zap.Error(err))
+		}
 	}
 
 	logger.Info("Server exited")
 }
+
+func runMigrations(ctx context.Context, pgDbContext *postgresql.PostgresContext) error {
+	sqlMigrations, err := postgresql.LoadSQLMigrations(migrations.Files)
+	if err != nil {
+		return err
+	}
+
+	all := append(sqlMigrations,
+		pgrepository.AddressBackfillMigration(),
+		pgrepository.OrderEventBackfillMigration(),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	return pgDbContext.Migrate(ctx, all)
+}
@@ -0,0 +1,10 @@
+// Package configs embeds the default configuration files into the binary, for
+// when it runs without the configs directory.
+package configs
+
+import _ "embed"
+
+// Logger is the default logger config, configs/logger_config.json.
+//
+//go:embed logger_config.json
+var Logger []byte
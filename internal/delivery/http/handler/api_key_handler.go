@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/apikey"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type APIKeyHandler struct {
+	manageAPIKeysUseCase *apikey.ManageAPIKeysUseCase
+}
+
+func NewAPIKeyHandler(manageAPIKeysUseCase *apikey.ManageAPIKeysUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		manageAPIKeysUseCase: manageAPIKeysUseCase,
+	}
+}
+
+type rotateAPIKeyRequest struct {
+	GracePeriodSeconds int `json:"grace_period_seconds"`
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var input apikey.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	issued, err := h.manageAPIKeysUseCase.Create(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created; store the key now, it is not shown again",
+		"api_key": issued.APIKey,
+		"key":     issued.Key,
+	})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.manageAPIKeysUseCase.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	// The body is optional; without it the old key stops working at once
+	var req rotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	grace := time.Duration(req.GracePeriodSeconds) * time.Second
+	issued, err := h.manageAPIKeysUseCase.Rotate(c.Request.Context(), keyID, grace)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key rotated; store the key now, it is not shown again",
+		"api_key": issued.APIKey,
+		"key":     issued.Key,
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.manageAPIKeysUseCase.Revoke(c.Request.Context(), keyID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+func (h *APIKeyHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrInvalidAPIKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrAPIKeyRevoked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Error("API key request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/problem"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// writeAuthError answers the errors of the authentication and permission
+// checks in the use cases. It reports whether err was one of them.
+func writeAuthError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, identity.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, authz.ErrForbidden):
+		problem.Forbidden(c, err)
+	default:
+		return false
+	}
+	return true
+}
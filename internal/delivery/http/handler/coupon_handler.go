@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/promotion"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type CouponHandler struct {
+	manageCouponsUseCase *promotion.ManageCouponsUseCase
+}
+
+func NewCouponHandler(manageCouponsUseCase *promotion.ManageCouponsUseCase) *CouponHandler {
+	return &CouponHandler{
+		manageCouponsUseCase: manageCouponsUseCase,
+	}
+}
+
+func (h *CouponHandler) CreateCoupon(c *gin.Context) {
+	var input promotion.CouponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	coupon, err := h.manageCouponsUseCase.Create(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Coupon created successfully",
+		"coupon":  coupon,
+	})
+}
+
+func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+
+	var input promotion.CouponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	coupon, err := h.manageCouponsUseCase.Update(c.Request.Context(), couponID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupon": coupon})
+}
+
+func (h *CouponHandler) GetCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+
+	coupon, err := h.manageCouponsUseCase.Get(c.Request.Context(), couponID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupon": coupon})
+}
+
+func (h *CouponHandler) ListCoupons(c *gin.Context) {
+	page, err := queryInt(c, "page")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	pageSize, err := queryInt(c, "page_size")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+
+	output, err := h.manageCouponsUseCase.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *CouponHandler) DeactivateCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+
+	coupon, err := h.manageCouponsUseCase.Deactivate(c.Request.Context(), couponID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Coupon deactivated",
+		"coupon":  coupon,
+	})
+}
+
+func (h *CouponHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrInvalidCoupon), errors.Is(err, promotion.ErrInvalidPagination):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCouponNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCouponCodeTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Coupon request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
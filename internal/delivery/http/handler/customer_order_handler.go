@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/order"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// CustomerOrderHandler serves the /api/me routes of the authenticated user.
+type CustomerOrderHandler struct {
+	customerOrdersUseCase *usecase.CustomerOrdersUseCase
+}
+
+func NewCustomerOrderHandler(customerOrdersUseCase *usecase.CustomerOrdersUseCase) *CustomerOrderHandler {
+	return &CustomerOrderHandler{
+		customerOrdersUseCase: customerOrdersUseCase,
+	}
+}
+
+func (h *CustomerOrderHandler) ListOrders(c *gin.Context) {
+	page, err := queryInt(c, "page")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	pageSize, err := queryInt(c, "page_size")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+
+	output, err := h.customerOrdersUseCase.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *CustomerOrderHandler) GetOrder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.customerOrdersUseCase.Get(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": order})
+}
+
+func (h *CustomerOrderHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidPage), errors.Is(err, usecase.ErrInvalidPageSize):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	default:
+		logger.Error("Customer order request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/health"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// HealthHandler serves the probes of load balancers and orchestrators.
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Live handles GET /healthz. It only tells that the process serves requests
+// and never checks dependencies, so an outage of the database does not get
+// every instance restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready handles GET /readyz with the status and latency of each dependency.
+// It answers 503 while starting, shutting down or missing a dependency.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+
+	for name, dependency := range report.Dependencies {
+		if dependency.Err != nil {
+			logger.FromContext(c.Request.Context()).Warn("Dependency check failed",
+				zap.String("dependency", name),
+				zap.Float64("latency_ms", dependency.LatencyMS),
+				zap.Error(dependency.Err),
+			)
+		}
+	}
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Started handles GET /startupz. It answers 503 until the database
+// migrations are applied.
+func (h *HealthHandler) Started(c *gin.Context) {
+	if !h.checker.Started() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": health.StatusStarting})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "started"})
+}
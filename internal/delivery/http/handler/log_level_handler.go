@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// LogLevelHandler reads and changes the log levels of this instance. The
+// change is lost on restart; SIGHUP restores the levels of the config file.
+type LogLevelHandler struct{}
+
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+func (h *LogLevelHandler) GetLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, logger.GetLevels())
+}
+
+func (h *LogLevelHandler) SetLevels(c *gin.Context) {
+	var levels logger.Levels
+	if err := c.ShouldBindJSON(&levels); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := logger.SetLevels(levels); err != nil {
+		if errors.Is(err, logger.ErrInvalidLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error("Failed to set log levels", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	current := logger.GetLevels()
+	logger.FromContext(c.Request.Context()).Info("Log levels changed",
+		zap.String("level", current.Level),
+		zap.Any("components", current.Components),
+	)
+	c.JSON(http.StatusOK, current)
+}
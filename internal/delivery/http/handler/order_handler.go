@@ -1,23 +1,30 @@
 package handler
 
 import (
-	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/order"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 
 	// "github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
 type OrderHandler struct {
-	createOrderUseCase *usecase.CreateOrderUseCase
+	createOrderUseCase  *usecase.CreateOrderUseCase
+	searchOrdersUseCase *usecase.SearchOrdersUseCase
 }
 
-func NewOrderHandler(createOrderUseCase *usecase.CreateOrderUseCase, logger *zap.Logger) *OrderHandler {
+func NewOrderHandler(
+	createOrderUseCase *usecase.CreateOrderUseCase,
+	searchOrdersUseCase *usecase.SearchOrdersUseCase,
+) *OrderHandler {
 	return &OrderHandler{
-		createOrderUseCase: createOrderUseCase,
+		createOrderUseCase:  createOrderUseCase,
+		searchOrdersUseCase: searchOrdersUseCase,
 	}
 }
 
@@ -31,20 +38,35 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	if input.ShippingAddress == nil && input.ShippingAddr != "" {
+		c.Header("Deprecation", "true")
+		c.Header("Warning", `299 - "shipping_addr is deprecated, use shipping_address"`)
+	}
+
 	// Execute the use case
-	orderData, err := h.createOrderUseCase.Execute(context.Background(), input)
+	orderData, err := h.createOrderUseCase.Execute(c.Request.Context(), input)
 	if err != nil {
 		logger.Error("Failed to create order", zap.Error(err))
 
+		if writeAuthError(c, err) {
+			return
+		}
+
 		// Handle specific errors
-		switch err {
-		case usecase.ErrEmptyOrder:
+		switch {
+		case errors.Is(err, usecase.ErrEmptyOrder):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case usecase.ErrInvalidQuantity:
+		case errors.Is(err, usecase.ErrInvalidQuantity):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case usecase.ErrInsufficientStock:
+		case errors.Is(err, usecase.ErrMissingAddress), errors.Is(err, entity.ErrInvalidAddress):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, entity.ErrCouponNotApplicable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, entity.ErrNoShippingAvailable), errors.Is(err, entity.ErrShippingMethodUnavailable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrInsufficientStock), errors.Is(err, entity.ErrCouponUsageLimitReached):
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		case usecase.ErrProductNotFound:
+		case errors.Is(err, usecase.ErrProductNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
@@ -59,6 +81,56 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	})
 }
 
+func (h *OrderHandler) SearchOrders(c *gin.Context) {
+	page, err := queryInt(c, "page")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	pageSize, err := queryInt(c, "page_size")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+
+	input := usecase.SearchOrdersInput{
+		Query:    c.Query("q"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	output, err := h.searchOrdersUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		if writeAuthError(c, err) {
+			return
+		}
+
+		switch {
+		case errors.Is(err, usecase.ErrEmptySearchQuery),
+			errors.Is(err, usecase.ErrInvalidPage),
+			errors.Is(err, usecase.ErrInvalidPageSize):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error("Failed to search orders", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// queryInt reads an optional integer query parameter, returning zero when it
+// is absent so that the use case can apply its defaults.
+func queryInt(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
 //func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 //	var input order.CreateOrderInput
 //	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/order"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type PaymentHandler struct {
+	checkoutUseCase *usecase.CheckoutUseCase
+}
+
+func NewPaymentHandler(checkoutUseCase *usecase.CheckoutUseCase) *PaymentHandler {
+	return &PaymentHandler{
+		checkoutUseCase: checkoutUseCase,
+	}
+}
+
+// Checkout handles POST /orders/:id/checkout and its older alias POST
+// /orders/:id/payments. Both run the checkout saga, so every paid order has
+// its stock reserved.
+func (h *PaymentHandler) Checkout(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var input usecase.CheckoutInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.OrderID = orderID
+
+	output, err := h.checkoutUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *PaymentHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, usecase.ErrMissingPaymentMethod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrPaymentDeclined):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotPayable), errors.Is(err, entity.ErrPaymentInProgress),
+		errors.Is(err, entity.ErrInsufficientStock), errors.Is(err, entity.ErrSagaInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrPaymentTimeout), errors.Is(err, entity.ErrSagaTimeout):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Payment request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
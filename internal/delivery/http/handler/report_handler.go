@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/reporting"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// ReportHandler serves queries from the read models. It is kept apart from
+// the order handlers, which work on the write side.
+type ReportHandler struct {
+	listUserOrdersUseCase *reporting.ListUserOrdersUseCase
+	salesReportUseCase    *reporting.SalesReportUseCase
+	analyticsUseCase      *reporting.SalesAnalyticsUseCase
+}
+
+func NewReportHandler(
+	listUserOrdersUseCase *reporting.ListUserOrdersUseCase,
+	salesReportUseCase *reporting.SalesReportUseCase,
+	analyticsUseCase *reporting.SalesAnalyticsUseCase,
+) *ReportHandler {
+	return &ReportHandler{
+		listUserOrdersUseCase: listUserOrdersUseCase,
+		salesReportUseCase:    salesReportUseCase,
+		analyticsUseCase:      analyticsUseCase,
+	}
+}
+
+func (h *ReportHandler) ListUserOrders(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, err := queryInt(c, "page")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	pageSize, err := queryInt(c, "page_size")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+
+	output, err := h.listUserOrdersUseCase.Execute(c.Request.Context(), reporting.ListUserOrdersInput{
+		UserID:   userID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ReportHandler) DailySales(c *gin.Context) {
+	output, err := h.salesReportUseCase.DailySales(c.Request.Context(), reporting.SalesReportInput{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ReportHandler) CategorySales(c *gin.Context) {
+	output, err := h.salesReportUseCase.DailyCategorySales(c.Request.Context(), reporting.SalesReportInput{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ReportHandler) ProjectionLag(c *gin.Context) {
+	lags, err := h.salesReportUseCase.ProjectionLag(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"projections": lags})
+}
+
+// Revenue reports order count, revenue and average order value per day,
+// week or month, e.g. GET /api/reports/revenue?from=2025-01-01&to=2025-03-31&interval=week&tz=Europe/Riga
+func (h *ReportHandler) Revenue(c *gin.Context) {
+	output, err := h.analyticsUseCase.Revenue(c.Request.Context(), reporting.RevenueInput{
+		ReportInput: reportInput(c),
+		Interval:    entity.ReportInterval(c.Query("interval")),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ReportHandler) TopProducts(c *gin.Context) {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	output, err := h.analyticsUseCase.TopProducts(c.Request.Context(), reporting.TopProductsInput{
+		ReportInput: reportInput(c),
+		By:          entity.TopProductsOrder(c.Query("by")),
+		Limit:       limit,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ReportHandler) StatusFunnel(c *gin.Context) {
+	output, err := h.analyticsUseCase.StatusFunnel(c.Request.Context(), reportInput(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func reportInput(c *gin.Context) reporting.ReportInput {
+	return reporting.ReportInput{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Timezone: c.Query("tz"),
+	}
+}
+
+func (h *ReportHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, reporting.ErrInvalidPagination),
+		errors.Is(err, reporting.ErrInvalidDateRange),
+		errors.Is(err, reporting.ErrInvalidTimezone),
+		errors.Is(err, reporting.ErrInvalidInterval),
+		errors.Is(err, reporting.ErrInvalidRanking),
+		errors.Is(err, reporting.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Report request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
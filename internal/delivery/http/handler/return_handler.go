@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/returns"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type ReturnHandler struct {
+	requestReturnUseCase *returns.RequestReturnUseCase
+	reviewReturnUseCase  *returns.ReviewReturnUseCase
+	issueRefundUseCase   *returns.IssueRefundUseCase
+	listReturnsUseCase   *returns.ListReturnsUseCase
+}
+
+func NewReturnHandler(
+	requestReturnUseCase *returns.RequestReturnUseCase,
+	reviewReturnUseCase *returns.ReviewReturnUseCase,
+	issueRefundUseCase *returns.IssueRefundUseCase,
+	listReturnsUseCase *returns.ListReturnsUseCase,
+) *ReturnHandler {
+	return &ReturnHandler{
+		requestReturnUseCase: requestReturnUseCase,
+		reviewReturnUseCase:  reviewReturnUseCase,
+		issueRefundUseCase:   issueRefundUseCase,
+		listReturnsUseCase:   listReturnsUseCase,
+	}
+}
+
+func (h *ReturnHandler) RequestReturn(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var input returns.RequestReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.OrderID = orderID
+
+	ret, err := h.requestReturnUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"return": ret})
+}
+
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	list, err := h.listReturnsUseCase.ListReturns(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"returns": list})
+}
+
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	returnID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return ID"})
+		return
+	}
+
+	ret, err := h.listReturnsUseCase.GetReturn(c.Request.Context(), returnID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.reviewReturn(c, h.reviewReturnUseCase.Approve)
+}
+
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.reviewReturn(c, h.reviewReturnUseCase.Reject)
+}
+
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	h.reviewReturn(c, h.reviewReturnUseCase.Receive)
+}
+
+func (h *ReturnHandler) reviewReturn(
+	c *gin.Context,
+	step func(ctx context.Context, input returns.ReviewReturnInput) (*entity.ReturnRequest, error),
+) {
+	returnID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return ID"})
+		return
+	}
+
+	// The body is optional and only carries a note and the restock flag
+	var input returns.ReviewReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.ReturnID = returnID
+
+	ret, err := step(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+func (h *ReturnHandler) IssueRefund(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var input returns.IssueRefundInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.OrderID = orderID
+
+	refund, err := h.issueRefundUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"refund": refund})
+}
+
+func (h *ReturnHandler) ListRefunds(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	refunds, err := h.listReturnsUseCase.ListRefunds(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
+
+func (h *ReturnHandler) UpdateRefund(c *gin.Context) {
+	refundID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refund ID"})
+		return
+	}
+
+	var input returns.UpdateRefundInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.RefundID = refundID
+
+	refund, err := h.issueRefundUseCase.UpdateStatus(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refund": refund})
+}
+
+func (h *ReturnHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrInvalidReturn), errors.Is(err, entity.ErrInvalidRefund):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotFound), errors.Is(err, entity.ErrReturnNotFound),
+		errors.Is(err, entity.ErrRefundNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotReturnable), errors.Is(err, entity.ErrInvalidReturnTransition),
+		errors.Is(err, entity.ErrInvalidRefundTransition), errors.Is(err, entity.ErrRefundExceedsPayment),
+		errors.Is(err, entity.ErrReturnAlreadyRefunded), errors.Is(err, returns.ErrReturnNotReceived),
+		errors.Is(err, entity.ErrPaymentDeclined):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrPaymentTimeout):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Return request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/order"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type ShipmentHandler struct {
+	createShipmentUseCase  *usecase.CreateShipmentUseCase
+	confirmDeliveryUseCase *usecase.ConfirmDeliveryUseCase
+	listShipmentsUseCase   *usecase.ListShipmentsUseCase
+}
+
+func NewShipmentHandler(
+	createShipmentUseCase *usecase.CreateShipmentUseCase,
+	confirmDeliveryUseCase *usecase.ConfirmDeliveryUseCase,
+	listShipmentsUseCase *usecase.ListShipmentsUseCase,
+) *ShipmentHandler {
+	return &ShipmentHandler{
+		createShipmentUseCase:  createShipmentUseCase,
+		confirmDeliveryUseCase: confirmDeliveryUseCase,
+		listShipmentsUseCase:   listShipmentsUseCase,
+	}
+}
+
+func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var input usecase.CreateShipmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.OrderID = orderID
+
+	output, err := h.createShipmentUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+func (h *ShipmentHandler) ListShipments(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	shipments, err := h.listShipmentsUseCase.Execute(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+
+func (h *ShipmentHandler) ConfirmDelivery(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	shipmentID, err := strconv.ParseInt(c.Param("shipmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shipment ID"})
+		return
+	}
+
+	// The body is optional and only carries an explicit delivery time
+	var input usecase.ConfirmDeliveryInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn("Invalid request payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.OrderID = orderID
+	input.ShipmentID = shipmentID
+
+	output, err := h.confirmDeliveryUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *ShipmentHandler) handleError(c *gin.Context, err error) {
+	if writeAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrInvalidShipment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotFound), errors.Is(err, entity.ErrShipmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotShippable), errors.Is(err, entity.ErrShipmentDelivered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Shipment request failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/shipping"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ShippingHandler struct {
+	quoteShippingUseCase *shipping.QuoteShippingUseCase
+}
+
+func NewShippingHandler(quoteShippingUseCase *shipping.QuoteShippingUseCase) *ShippingHandler {
+	return &ShippingHandler{
+		quoteShippingUseCase: quoteShippingUseCase,
+	}
+}
+
+// GetQuotes handles GET /api/shipping/quotes?country=LV&postal_code=LV-1010&items=12:2,15:1
+// where items lists the cart as product_id:quantity pairs.
+func (h *ShippingHandler) GetQuotes(c *gin.Context) {
+	items, err := parseCartItems(c.Query("items"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid items, expected product_id:quantity pairs"})
+		return
+	}
+
+	input := shipping.QuoteShippingInput{
+		Country:    c.Query("country"),
+		Region:     c.Query("region"),
+		PostalCode: c.Query("postal_code"),
+		Items:      items,
+	}
+
+	quotes, err := h.quoteShippingUseCase.Execute(c.Request.Context(), input)
+	if err != nil {
+		if writeAuthError(c, err) {
+			return
+		}
+
+		switch {
+		case errors.Is(err, shipping.ErrEmptyCart),
+			errors.Is(err, shipping.ErrInvalidQuantity),
+			errors.Is(err, shipping.ErrMissingCountry):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, shipping.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, entity.ErrNoShippingAvailable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			logger.Error("Failed to quote shipping", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes})
+}
+
+func parseCartItems(value string) ([]shipping.CartItem, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	pairs := strings.Split(value, ",")
+	items := make([]shipping.CartItem, 0, len(pairs))
+	for _, pair := range pairs {
+		id, qty, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found {
+			return nil, errors.New("missing quantity")
+		}
+
+		productID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := strconv.Atoi(qty)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, shipping.CartItem{ProductID: productID, Quantity: quantity})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// TokenVerifier validates a bearer token and returns the caller it
+// identifies.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*identity.Identity, error)
+}
+
+// KeyAuthenticator validates an API key and returns the service it
+// identifies.
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*identity.Identity, error)
+}
+
+// Authenticate rejects requests without a valid API key or bearer token and
+// stores the caller's identity in the request context. An X-API-Key header
+// takes precedence over the Authorization header.
+func Authenticate(verifier TokenVerifier, keys KeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			id, err := keys.Authenticate(c.Request.Context(), key)
+			if err != nil {
+				logger.Debug("Rejected API key", zap.Error(err), zap.String("path", c.FullPath()))
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired api key"})
+				return
+			}
+
+			c.Request = c.Request.WithContext(withCaller(c.Request.Context(), id))
+			c.Next()
+			return
+		}
+
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": identity.ErrUnauthenticated.Error()})
+			return
+		}
+
+		id, err := verifier.Verify(c.Request.Context(), token)
+		if err != nil {
+			logger.Debug("Rejected bearer token", zap.Error(err), zap.String("path", c.FullPath()))
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(withCaller(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// withCaller stores id in ctx and adds its subject to the request logger.
+func withCaller(ctx context.Context, id *identity.Identity) context.Context {
+	ctx = logger.WithContext(ctx, zap.String("subject", id.Subject))
+	return identity.WithIdentity(ctx, id)
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/problem"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Authorize grants the caller the permissions of their roles under policy
+// and the scopes of their credential. It runs after Authenticate.
+func Authorize(policy *authz.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, ok := identity.FromContext(c.Request.Context()); ok {
+			id.Permissions = policy.Grants(id.Roles, id.Scopes)
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission rejects callers lacking p with a 403 problem response.
+// Use cases check the permission again, so this only fails requests early.
+func RequirePermission(p authz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := authz.Require(c.Request.Context(), p)
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, authz.ErrForbidden):
+			problem.Forbidden(c, err)
+		default:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		}
+	}
+}
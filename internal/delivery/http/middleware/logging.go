@@ -1,30 +0,0 @@
-package middleware
-
-import (
-	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
-	"go.uber.org/zap"
-	"net/http"
-	"time"
-)
-
-func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Log incoming request
-		logger.Info("Incoming request",
-			zap.String("method", r.Method),
-			zap.String("path", r.URL.Path),
-			zap.String("remote_addr", r.RemoteAddr),
-		)
-
-		next.ServeHTTP(w, r)
-
-		// Log request completion
-		logger.Info("Request completed",
-			zap.String("method", r.Method),
-			zap.String("path", r.URL.Path),
-			zap.Duration("duration", time.Since(start)),
-		)
-	})
-}
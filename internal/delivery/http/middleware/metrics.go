@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// Metrics counts every request and its latency per route template and
+// status. Requests matching no route share the route "unmatched".
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
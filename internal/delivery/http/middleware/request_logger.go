@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogOptions keeps frequent, uninteresting requests such as health
+// checks out of the log. Requests to QuietPaths are logged once every
+// QuietSampleEvery requests, or never when it is zero; failed ones are
+// always logged.
+type RequestLogOptions struct {
+	QuietPaths       []string
+	QuietSampleEvery uint64
+}
+
+// RequestLogger logs every request through the zap logger. It reuses the
+// X-Request-ID of the caller or generates one, echoes it in the response
+// and attaches a logger carrying it to the request context, so everything
+// logged while serving the request can be correlated.
+func RequestLogger(opts RequestLogOptions) gin.HandlerFunc {
+	var quietCount atomic.Uint64
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), zap.String("request_id", requestID)))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		if status < http.StatusInternalServerError && slices.Contains(opts.QuietPaths, route) {
+			if opts.QuietSampleEvery == 0 || quietCount.Add(1)%opts.QuietSampleEvery != 1 {
+				return
+			}
+		}
+
+		// Read the context after the handlers ran; Authenticate stores the
+		// caller in it
+		ctx := c.Request.Context()
+		fields := []zapcore.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("bytes", max(c.Writer.Size(), 0)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if id, ok := identity.FromContext(ctx); ok && id.UserID > 0 {
+			fields = append(fields, zap.Int64("user_id", id.UserID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		log := logger.FromContext(ctx)
+		switch {
+		case status >= http.StatusInternalServerError:
+			// The handler logged the cause; the stack of this middleware
+			// would add nothing
+			log.WithOptions(zap.AddStacktrace(zapcore.FatalLevel)).Error("Request failed", fields...)
+		case status >= http.StatusBadRequest:
+			log.Warn("Request rejected", fields...)
+		default:
+			log.Info("Request completed", fields...)
+		}
+	}
+}
+
+// validRequestID accepts ids of printable ASCII up to 128 characters, so a
+// caller cannot inject line breaks or huge values into the log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
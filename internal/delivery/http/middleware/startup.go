@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/problem"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/health"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequireStarted answers 503 until the checker is marked started, so that no
+// request reaches a database whose migrations are still being applied.
+func RequireStarted(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checker.Started() {
+			c.Header("Retry-After", "5")
+			problem.Write(c, problem.Details{
+				Type:   "https://matrix-orders.local/problems/starting",
+				Title:  "Service Unavailable",
+				Status: http.StatusServiceUnavailable,
+				Detail: "The service is starting; retry in a few seconds.",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+// Package problem writes RFC 9457 problem details responses.
+package problem
+
+import (
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// Details is the body of a problem response. Extension members are added to
+// Extensions.
+type Details struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// Write sends d and aborts the request.
+func Write(c *gin.Context, d Details) {
+	if d.Instance == "" {
+		d.Instance = c.Request.URL.Path
+	}
+
+	body := gin.H{
+		"type":     d.Type,
+		"title":    d.Title,
+		"status":   d.Status,
+		"instance": d.Instance,
+	}
+	if d.Detail != "" {
+		body["detail"] = d.Detail
+	}
+	for k, v := range d.Extensions {
+		body[k] = v
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(d.Status, body)
+}
+
+// Forbidden answers 403 naming the permission the caller is missing.
+func Forbidden(c *gin.Context, err error) {
+	var forbidden *authz.ForbiddenError
+	permission := ""
+	if errors.As(err, &forbidden) {
+		permission = string(forbidden.Permission)
+	}
+
+	Write(c, Details{
+		Type:   "https://matrix-orders.local/problems/forbidden",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: "The caller's roles do not grant " + permission + ".",
+		Extensions: map[string]any{
+			"missing_permission": permission,
+		},
+	})
+}
@@ -1,31 +1,117 @@
 package router
 
 import (
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/handler"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/delivery/http/middleware"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/health"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
-// func SetupRouter(orderHandler *handler.OrderHandler) *gin.Engine {
-func SetupRouter() *gin.Engine {
+// SetupRouter builds the routes of the API. Client addresses, e.g. for rate
+// limits, are taken from X-Forwarded-For only behind trustedProxies.
+func SetupRouter(
+	trustedProxies []string,
+	verifier middleware.TokenVerifier,
+	keys middleware.KeyAuthenticator,
+	policy *authz.Policy,
+	limiter *ratelimit.Limiter,
+	healthChecker *health.Checker,
+	orderHandler *handler.OrderHandler,
+	couponHandler *handler.CouponHandler,
+	shippingHandler *handler.ShippingHandler,
+	shipmentHandler *handler.ShipmentHandler,
+	returnHandler *handler.ReturnHandler,
+	paymentHandler *handler.PaymentHandler,
+	reportHandler *handler.ReportHandler,
+	customerOrderHandler *handler.CustomerOrderHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	logLevelHandler *handler.LogLevelHandler,
+	healthHandler *handler.HealthHandler,
+) (*gin.Engine, error) {
 	r := gin.New() // or Default
-	r.Use(gin.Logger())
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(middleware.Tracing())
+	r.Use(middleware.RequestLogger(middleware.RequestLogOptions{
+		QuietPaths:       []string{"/healthz", "/readyz", "/startupz"},
+		QuietSampleEvery: 100,
+	}))
+	r.Use(middleware.Metrics())
 	r.Use(gin.Recovery()) // Protect against crashes
 
-	r.GET("/panic", func(c *gin.Context) {
-		panic("Something went wrong!")
-	})
+	// Probes
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+	r.GET("/startupz", healthHandler.Started)
 
-	// Health Check Route
-	r.GET("/api", HealthCheckHandler)
+	// Every other API route needs a bearer token or API key granting the
+	// route's permission, and is limited per client address and per caller.
+	// Until the migrations are applied these routes answer 503.
+	api := r.Group("/api",
+		middleware.RequireStarted(healthChecker),
+		middleware.RateLimitAddress(limiter),
+		middleware.Authenticate(verifier, keys),
+		middleware.Authorize(policy),
+		middleware.RateLimit(limiter),
+	)
+	allow := middleware.RequirePermission
 
 	// Order Routes
-	// r.POST("/api/orders", orderHandler.CreateOrder)
+	api.POST("/orders", allow(authz.OrdersCreate), orderHandler.CreateOrder)
+	api.GET("/orders/search", allow(authz.OrdersRead), orderHandler.SearchOrders)
+	api.POST("/orders/:id/shipments", allow(authz.ShipmentsWrite), shipmentHandler.CreateShipment)
+	api.GET("/orders/:id/shipments", allow(authz.ShipmentsRead), shipmentHandler.ListShipments)
+	api.POST("/orders/:id/shipments/:shipmentId/delivery", allow(authz.ShipmentsWrite), shipmentHandler.ConfirmDelivery)
+	api.POST("/orders/:id/payments", allow(authz.OrdersPay), paymentHandler.Checkout)
+	api.POST("/orders/:id/checkout", allow(authz.OrdersPay), paymentHandler.Checkout)
+	api.POST("/orders/:id/returns", allow(authz.ReturnsRequest), returnHandler.RequestReturn)
+	api.GET("/orders/:id/returns", allow(authz.ReturnsRead), returnHandler.ListReturns)
+	api.POST("/orders/:id/refunds", allow(authz.RefundsIssue), returnHandler.IssueRefund)
+	api.GET("/orders/:id/refunds", allow(authz.RefundsRead), returnHandler.ListRefunds)
 
-	return r
-}
+	// Return Routes
+	api.GET("/returns/:id", allow(authz.ReturnsRead), returnHandler.GetReturn)
+	api.POST("/returns/:id/approve", allow(authz.ReturnsReview), returnHandler.ApproveReturn)
+	api.POST("/returns/:id/reject", allow(authz.ReturnsReview), returnHandler.RejectReturn)
+	api.POST("/returns/:id/receive", allow(authz.ReturnsReceive), returnHandler.ReceiveReturn)
+
+	// Refund Routes
+	api.PATCH("/refunds/:id", allow(authz.RefundsIssue), returnHandler.UpdateRefund)
+
+	// Routes of the authenticated user
+	api.GET("/me/orders", allow(authz.OrdersReadOwn), customerOrderHandler.ListOrders)
+	api.GET("/me/orders/:id", allow(authz.OrdersReadOwn), customerOrderHandler.GetOrder)
+
+	// Read model queries; these trail the order events by a few seconds
+	api.GET("/users/:id/orders", allow(authz.OrdersRead), reportHandler.ListUserOrders)
+	api.GET("/reports/daily-sales", allow(authz.ReportsRead), reportHandler.DailySales)
+	api.GET("/reports/category-sales", allow(authz.ReportsRead), reportHandler.CategorySales)
+	api.GET("/reports/lag", allow(authz.ReportsRead), reportHandler.ProjectionLag)
+
+	// Report Routes, aggregated from the orders tables
+	api.GET("/reports/revenue", allow(authz.ReportsRead), reportHandler.Revenue)
+	api.GET("/reports/top-products", allow(authz.ReportsRead), reportHandler.TopProducts)
+	api.GET("/reports/funnel", allow(authz.ReportsRead), reportHandler.StatusFunnel)
+
+	// Shipping Routes
+	api.GET("/shipping/quotes", allow(authz.ShippingQuote), shippingHandler.GetQuotes)
 
-func HealthCheckHandler(c *gin.Context) {
-	response := gin.H{"message": "Hello, Gin!"}
+	// Admin Routes
+	admin := api.Group("/admin")
+	admin.POST("/coupons", allow(authz.CouponsManage), couponHandler.CreateCoupon)
+	admin.GET("/coupons", allow(authz.CouponsManage), couponHandler.ListCoupons)
+	admin.GET("/coupons/:id", allow(authz.CouponsManage), couponHandler.GetCoupon)
+	admin.PUT("/coupons/:id", allow(authz.CouponsManage), couponHandler.UpdateCoupon)
+	admin.DELETE("/coupons/:id", allow(authz.CouponsManage), couponHandler.DeactivateCoupon)
+	admin.POST("/api-keys", allow(authz.APIKeysManage), apiKeyHandler.CreateAPIKey)
+	admin.GET("/api-keys", allow(authz.APIKeysManage), apiKeyHandler.ListAPIKeys)
+	admin.POST("/api-keys/:id/rotate", allow(authz.APIKeysManage), apiKeyHandler.RotateAPIKey)
+	admin.DELETE("/api-keys/:id", allow(authz.APIKeysManage), apiKeyHandler.RevokeAPIKey)
+	admin.GET("/log-level", allow(authz.LoggingManage), logLevelHandler.GetLevels)
+	admin.PUT("/log-level", allow(authz.LoggingManage), logLevelHandler.SetLevels)
 
-	c.JSON(http.StatusOK, response)
+	return r, nil
 }
@@ -0,0 +1,277 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// Address is a postal address used for shipping and billing.
+type Address struct {
+	Name       string   `json:"name"`
+	Lines      []string `json:"lines"`
+	City       string   `json:"city"`
+	Region     string   `json:"region,omitempty"`
+	PostalCode string   `json:"postal_code,omitempty"`
+	Country    string   `json:"country"`
+	Phone      string   `json:"phone,omitempty"`
+}
+
+// isoCountryCodes lists the ISO 3166-1 alpha-2 country codes.
+var isoCountryCodes = toSet(strings.Fields(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL
+	BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV
+	CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD
+	GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM
+	IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK
+	LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW
+	MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR
+	PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS
+	ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY
+	UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`))
+
+// postalCodePatterns holds the postal code formats of the countries we ship
+// to most often. Other countries fall back to genericPostalCode.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"EE": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IE": regexp.MustCompile(`^[A-Z]\d[\dW] ?[A-Z\d]{4}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"LT": regexp.MustCompile(`^(LT-)?\d{5}$`),
+	"LV": regexp.MustCompile(`^(LV-)?\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+
+// countriesWithoutPostalCodes do not use postal codes, so none is required.
+var countriesWithoutPostalCodes = toSet([]string{
+	"AE", "AG", "AO", "BS", "BZ", "HK", "IE", "JM", "MO", "QA", "TT", "ZW",
+})
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9 ()-]{6,20}$`)
+
+// Normalize trims whitespace and canonicalizes the case of the country and
+// postal code.
+func (a *Address) Normalize() {
+	a.Name = strings.TrimSpace(a.Name)
+	lines := make([]string, 0, len(a.Lines))
+	for _, line := range a.Lines {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	a.Lines = lines
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Phone = strings.TrimSpace(a.Phone)
+}
+
+// Validate checks that the address is complete enough to print a carrier
+// label. Errors wrap ErrInvalidAddress.
+func (a *Address) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAddress)
+	}
+	if len(a.Lines) == 0 {
+		return fmt.Errorf("%w: at least one address line is required", ErrInvalidAddress)
+	}
+	if a.City == "" {
+		return fmt.Errorf("%w: city is required", ErrInvalidAddress)
+	}
+	if _, ok := isoCountryCodes[a.Country]; !ok {
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidAddress)
+	}
+	if err := validatePostalCode(a.Country, a.PostalCode); err != nil {
+		return err
+	}
+	if a.Phone != "" && !phonePattern.MatchString(a.Phone) {
+		return fmt.Errorf("%w: invalid phone number", ErrInvalidAddress)
+	}
+
+	return nil
+}
+
+func validatePostalCode(country, postalCode string) error {
+	if postalCode == "" {
+		if _, ok := countriesWithoutPostalCodes[country]; ok {
+			return nil
+		}
+		return fmt.Errorf("%w: postal code is required for %s", ErrInvalidAddress, country)
+	}
+
+	pattern, ok := postalCodePatterns[country]
+	if !ok {
+		pattern = genericPostalCode
+	}
+	if !pattern.MatchString(postalCode) {
+		return fmt.Errorf("%w: invalid postal code %q for %s", ErrInvalidAddress, postalCode, country)
+	}
+
+	return nil
+}
+
+// String formats the address on a single line, e.g. for search and for
+// clients still reading the legacy shipping_addr field.
+func (a Address) String() string {
+	parts := make([]string, 0, len(a.Lines)+4)
+	if a.Name != "" {
+		parts = append(parts, a.Name)
+	}
+	parts = append(parts, a.Lines...)
+	if a.City != "" {
+		parts = append(parts, a.City)
+	}
+	if region := strings.TrimSpace(a.Region + " " + a.PostalCode); region != "" {
+		parts = append(parts, region)
+	}
+	if a.Country != "" {
+		parts = append(parts, a.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countryNames maps common spellings found in legacy free text addresses to
+// their country code.
+var countryNames = map[string]string{
+	"AUSTRIA":                  "AT",
+	"BELGIUM":                  "BE",
+	"CANADA":                   "CA",
+	"ESTONIA":                  "EE",
+	"FRANCE":                   "FR",
+	"GERMANY":                  "DE",
+	"GREAT BRITAIN":            "GB",
+	"ITALY":                    "IT",
+	"LATVIA":                   "LV",
+	"LITHUANIA":                "LT",
+	"NETHERLANDS":              "NL",
+	"POLAND":                   "PL",
+	"SPAIN":                    "ES",
+	"SWEDEN":                   "SE",
+	"UK":                       "GB",
+	"UNITED KINGDOM":           "GB",
+	"UNITED STATES":            "US",
+	"UNITED STATES OF AMERICA": "US",
+	"USA":                      "US",
+}
+
+// ParseAddress makes a best-effort attempt to split a legacy free text
+// address such as "12 Main St, Springfield, IL 62704, USA" into its parts.
+// Whatever cannot be recognised is kept in Lines so no information is lost;
+// callers should Validate the result before relying on it.
+func ParseAddress(raw string) Address {
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	parts = removeEmpty(parts)
+
+	var addr Address
+	if len(parts) == 0 {
+		return addr
+	}
+
+	// Country
+	last := strings.ToUpper(parts[len(parts)-1])
+	if code, ok := countryNames[last]; ok {
+		addr.Country = code
+		parts = parts[:len(parts)-1]
+	} else if _, ok := isoCountryCodes[last]; ok && len(parts) > 1 {
+		addr.Country = last
+		parts = parts[:len(parts)-1]
+	}
+
+	// Postal code next to a region or city, e.g. "IL 62704" or "10115 Berlin"
+	if len(parts) > 1 {
+		fields := strings.Fields(parts[len(parts)-1])
+		if postalCode, rest, leading, ok := splitPostalCode(addr.Country, fields); ok {
+			addr.PostalCode = postalCode
+			parts = parts[:len(parts)-1]
+			if rest != "" && !leading && len(parts) > 1 {
+				addr.Region = rest
+			} else {
+				addr.City = rest
+			}
+		}
+	}
+
+	// City
+	if addr.City == "" && len(parts) > 1 {
+		addr.City = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	addr.Lines = parts
+	return addr
+}
+
+// splitPostalCode looks for a postal code at the end or, failing that, at the
+// start of fields and returns it together with the remaining text.
+func splitPostalCode(country string, fields []string) (postalCode, rest string, leading, ok bool) {
+	n := len(fields)
+	if n >= 2 && country != "" {
+		candidate := strings.ToUpper(fields[n-2] + " " + fields[n-1])
+		if validatePostalCode(country, candidate) == nil {
+			return candidate, strings.Join(fields[:n-2], " "), false, true
+		}
+	}
+	if n >= 1 {
+		candidate := strings.ToUpper(fields[n-1])
+		if looksLikePostalCode(country, candidate) {
+			return candidate, strings.Join(fields[:n-1], " "), false, true
+		}
+		candidate = strings.ToUpper(fields[0])
+		if n > 1 && looksLikePostalCode(country, candidate) {
+			return candidate, strings.Join(fields[1:], " "), true, true
+		}
+	}
+	return "", "", false, false
+}
+
+func looksLikePostalCode(country, value string) bool {
+	if country != "" {
+		return validatePostalCode(country, value) == nil
+	}
+	return len(value) >= 4 && genericPostalCode.MatchString(value) && strings.ContainsAny(value, "0123456789")
+}
+
+func removeEmpty(values []string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyRevoked  = errors.New("api key is revoked")
+)
+
+// APIKey authenticates a service rather than a user. Scopes are the
+// permissions the key grants.
+type APIKey struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RotatedToID *int64     `json:"rotated_to_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Validate checks the key definition. Errors wrap ErrInvalidAPIKey.
+func (k *APIKey) Validate(now time.Time) error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if len(k.Name) > 100 {
+		return fmt.Errorf("%w: name is longer than 100 characters", ErrInvalidAPIKey)
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidAPIKey)
+	}
+	return nil
+}
+
+// Active reports whether the key may authenticate requests at now.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil && !k.RevokedAt.After(now) {
+		return false
+	}
+	return k.ExpiresAt == nil || k.ExpiresAt.After(now)
+}
@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCouponNotFound          = errors.New("coupon not found")
+	ErrCouponCodeTaken         = errors.New("coupon code already exists")
+	ErrInvalidCoupon           = errors.New("invalid coupon")
+	ErrCouponNotApplicable     = errors.New("coupon cannot be applied to this order")
+	ErrCouponUsageLimitReached = errors.New("coupon usage limit reached")
+)
+
+type CouponType string
+
+const (
+	CouponTypePercentage   CouponType = "percentage"
+	CouponTypeFixedAmount  CouponType = "fixed_amount"
+	CouponTypeFreeShipping CouponType = "free_shipping"
+)
+
+type Coupon struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Description    string     `json:"description"`
+	Type           CouponType `json:"type"`
+	Value          float64    `json:"value"`
+	MinOrderValue  float64    `json:"min_order_value"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxUses        *int       `json:"max_uses,omitempty"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user,omitempty"`
+	TimesUsed      int        `json:"times_used"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// OrderDiscount is a discount line stored with an order.
+type OrderDiscount struct {
+	ID          int64      `json:"id"`
+	OrderID     int64      `json:"order_id"`
+	CouponID    int64      `json:"coupon_id"`
+	Code        string     `json:"code"`
+	Type        CouponType `json:"type"`
+	Description string     `json:"description"`
+	Amount      float64    `json:"amount"`
+}
+
+// NormalizeCouponCode returns the canonical form in which coupon codes are
+// stored and looked up.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the coupon definition. Errors wrap ErrInvalidCoupon.
+func (c *Coupon) Validate() error {
+	if c.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidCoupon)
+	}
+	if len(c.Code) > 50 {
+		return fmt.Errorf("%w: code must be at most 50 characters", ErrInvalidCoupon)
+	}
+
+	switch c.Type {
+	case CouponTypePercentage:
+		if c.Value <= 0 || c.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidCoupon)
+		}
+	case CouponTypeFixedAmount:
+		if c.Value <= 0 {
+			return fmt.Errorf("%w: amount must be greater than zero", ErrInvalidCoupon)
+		}
+	case CouponTypeFreeShipping:
+		c.Value = 0
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidCoupon, c.Type)
+	}
+
+	if c.MinOrderValue < 0 {
+		return fmt.Errorf("%w: minimum order value must not be negative", ErrInvalidCoupon)
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidUntil.After(*c.ValidFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidCoupon)
+	}
+	if c.MaxUses != nil && *c.MaxUses <= 0 {
+		return fmt.Errorf("%w: max_uses must be greater than zero", ErrInvalidCoupon)
+	}
+	if c.MaxUsesPerUser != nil && *c.MaxUsesPerUser <= 0 {
+		return fmt.Errorf("%w: max_uses_per_user must be greater than zero", ErrInvalidCoupon)
+	}
+
+	return nil
+}
+
+// Apply evaluates the coupon against an order subtotal and returns the
+// resulting discount line. userRedemptions is the number of times the user
+// has already redeemed the coupon. Errors wrap ErrCouponNotApplicable or
+// ErrCouponUsageLimitReached.
+func (c *Coupon) Apply(subtotal float64, userRedemptions int, now time.Time) (OrderDiscount, error) {
+	if !c.Active {
+		return OrderDiscount{}, fmt.Errorf("%w: coupon is not active", ErrCouponNotApplicable)
+	}
+	if c.ValidFrom != nil && now.Before(*c.ValidFrom) {
+		return OrderDiscount{}, fmt.Errorf("%w: coupon is not valid yet", ErrCouponNotApplicable)
+	}
+	if c.ValidUntil != nil && !now.Before(*c.ValidUntil) {
+		return OrderDiscount{}, fmt.Errorf("%w: coupon has expired", ErrCouponNotApplicable)
+	}
+	if subtotal < c.MinOrderValue {
+		return OrderDiscount{}, fmt.Errorf("%w: order total must be at least %.2f", ErrCouponNotApplicable, c.MinOrderValue)
+	}
+	if c.MaxUses != nil && c.TimesUsed >= *c.MaxUses {
+		return OrderDiscount{}, ErrCouponUsageLimitReached
+	}
+	if c.MaxUsesPerUser != nil && userRedemptions >= *c.MaxUsesPerUser {
+		return OrderDiscount{}, ErrCouponUsageLimitReached
+	}
+
+	var amount float64
+	switch c.Type {
+	case CouponTypePercentage:
+		amount = RoundMoney(subtotal * c.Value / 100)
+	case CouponTypeFixedAmount:
+		amount = min(c.Value, subtotal)
+	}
+
+	return OrderDiscount{
+		CouponID:    c.ID,
+		Code:        c.Code,
+		Type:        c.Type,
+		Description: c.Description,
+		Amount:      amount,
+	}, nil
+}
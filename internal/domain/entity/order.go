@@ -1,17 +1,21 @@
 package entity
 
 import (
+	"errors"
 	"time"
 )
 
+var ErrOrderNotFound = errors.New("order not found")
+
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusPaid      OrderStatus = "paid"
-	OrderStatusShipped   OrderStatus = "shipped"
-	OrderStatusDelivered OrderStatus = "delivered"
-	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusPending          OrderStatus = "pending"
+	OrderStatusPaid             OrderStatus = "paid"
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped"
+	OrderStatusShipped          OrderStatus = "shipped"
+	OrderStatusDelivered        OrderStatus = "delivered"
+	OrderStatusCancelled        OrderStatus = "cancelled"
 )
 
 type OrderItem struct {
@@ -21,15 +25,27 @@ type OrderItem struct {
 	Quantity   int     `json:"quantity"`
 	UnitPrice  float64 `json:"unit_price"`
 	TotalPrice float64 `json:"total_price"`
+	TaxRate    float64 `json:"tax_rate"`
+	TaxAmount  float64 `json:"tax_amount"`
 }
 
 type Order struct {
-	ID           int64       `json:"id"`
-	UserID       int64       `json:"user_id"`
-	Items        []OrderItem `json:"items"`
-	TotalAmount  float64     `json:"total_amount"`
-	Status       OrderStatus `json:"status"`
-	ShippingAddr string      `json:"shipping_addr"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
+	ID               int64            `json:"id"`
+	UserID           int64            `json:"user_id"`
+	Items            []OrderItem      `json:"items"`
+	Discounts        []OrderDiscount  `json:"discounts"`
+	Subtotal         float64          `json:"subtotal"`
+	DiscountAmount   float64          `json:"discount_amount"`
+	TaxAmount        float64          `json:"tax_amount"`
+	PricesIncludeTax bool             `json:"prices_include_tax"`
+	TaxSummary       []TaxSummaryLine `json:"tax_summary"`
+	ShippingMethod   string           `json:"shipping_method"`
+	ShippingCost     float64          `json:"shipping_cost"`
+	TotalAmount      float64          `json:"total_amount"`
+	Status           OrderStatus      `json:"status"`
+	ShippingAddress  Address          `json:"shipping_address"`
+	BillingAddress   Address          `json:"billing_address"`
+	Notes            string           `json:"notes"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
 }
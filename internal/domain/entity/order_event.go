@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrConcurrencyConflict = errors.New("order was changed concurrently")
+	ErrInvalidOrderEvent   = errors.New("invalid order event")
+)
+
+type OrderEventType string
+
+const (
+	OrderEventCreated       OrderEventType = "order_created"
+	OrderEventStatusChanged OrderEventType = "order_status_changed"
+)
+
+// OrderEvent is an immutable fact about an order. Sequence numbers start at
+// 1 and have no gaps within an order; ID orders events across all orders.
+type OrderEvent struct {
+	ID         int64           `json:"id"`
+	OrderID    int64           `json:"order_id"`
+	Sequence   int64           `json:"sequence"`
+	Type       OrderEventType  `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// OrderStatusChanged is the data of an OrderEventStatusChanged event.
+type OrderStatusChanged struct {
+	From OrderStatus `json:"from"`
+	To   OrderStatus `json:"to"`
+}
+
+// NewOrderCreatedEvent records the order as placed, including the IDs of
+// its items and discounts.
+func NewOrderCreatedEvent(order *Order) (OrderEvent, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return OrderEvent{}, err
+	}
+	return OrderEvent{
+		OrderID:    order.ID,
+		Type:       OrderEventCreated,
+		Data:       data,
+		OccurredAt: order.CreatedAt,
+	}, nil
+}
+
+func NewOrderStatusChangedEvent(orderID int64, from, to OrderStatus, at time.Time) (OrderEvent, error) {
+	data, err := json.Marshal(OrderStatusChanged{From: from, To: to})
+	if err != nil {
+		return OrderEvent{}, err
+	}
+	return OrderEvent{
+		OrderID:    orderID,
+		Type:       OrderEventStatusChanged,
+		Data:       data,
+		OccurredAt: at,
+	}, nil
+}
+
+// OrderAggregate is an order rebuilt from its events. Version is the
+// sequence number of the last event applied.
+type OrderAggregate struct {
+	Order   *Order `json:"order"`
+	Version int64  `json:"version"`
+}
+
+// Apply folds the next event of the order into the aggregate.
+func (a *OrderAggregate) Apply(event OrderEvent) error {
+	if event.Sequence != a.Version+1 {
+		return fmt.Errorf("%w: expected sequence %d, got %d", ErrInvalidOrderEvent, a.Version+1, event.Sequence)
+	}
+
+	switch event.Type {
+	case OrderEventCreated:
+		if a.Order != nil {
+			return fmt.Errorf("%w: order %d created twice", ErrInvalidOrderEvent, event.OrderID)
+		}
+		order := &Order{}
+		if err := json.Unmarshal(event.Data, order); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOrderEvent, err)
+		}
+		a.Order = order
+	case OrderEventStatusChanged:
+		if a.Order == nil {
+			return fmt.Errorf("%w: order %d changed before it was created", ErrInvalidOrderEvent, event.OrderID)
+		}
+		var change OrderStatusChanged
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOrderEvent, err)
+		}
+		a.Order.Status = change.To
+		a.Order.UpdatedAt = event.OccurredAt
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidOrderEvent, event.Type)
+	}
+
+	a.Version = event.Sequence
+	return nil
+}
+
+// ChangeStatus applies a change to status and returns its event, numbered
+// after the events applied so far. It returns nil when the order already
+// has that status.
+func (a *OrderAggregate) ChangeStatus(status OrderStatus, at time.Time) (*OrderEvent, error) {
+	if a.Order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if a.Order.Status == status {
+		return nil, nil
+	}
+
+	event, err := NewOrderStatusChangedEvent(a.Order.ID, a.Order.Status, status, at)
+	if err != nil {
+		return nil, err
+	}
+	event.Sequence = a.Version + 1
+	if err := a.Apply(event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// RehydrateOrder replays events on top of an optional snapshot.
+func RehydrateOrder(snapshot *OrderAggregate, events []OrderEvent) (*OrderAggregate, error) {
+	aggregate := &OrderAggregate{}
+	if snapshot != nil {
+		*aggregate = *snapshot
+	}
+
+	for _, event := range events {
+		if err := aggregate.Apply(event); err != nil {
+			return nil, err
+		}
+	}
+
+	if aggregate.Order == nil {
+		return nil, ErrOrderNotFound
+	}
+	return aggregate, nil
+}
@@ -0,0 +1,204 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var eventTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func createdEvent(t *testing.T, sequence int64) OrderEvent {
+	t.Helper()
+
+	event, err := NewOrderCreatedEvent(&Order{ID: 7, UserID: 42, Status: OrderStatusPending, CreatedAt: eventTime})
+	if err != nil {
+		t.Fatalf("NewOrderCreatedEvent: %v", err)
+	}
+	event.Sequence = sequence
+	return event
+}
+
+func statusEvent(t *testing.T, sequence int64, from, to OrderStatus) OrderEvent {
+	t.Helper()
+
+	event, err := NewOrderStatusChangedEvent(7, from, to, eventTime.Add(time.Duration(sequence)*time.Minute))
+	if err != nil {
+		t.Fatalf("NewOrderStatusChangedEvent: %v", err)
+	}
+	event.Sequence = sequence
+	return event
+}
+
+func TestOrderAggregateApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  func(t *testing.T) []OrderEvent
+		status  OrderStatus
+		version int64
+		wantErr error
+	}{
+		{
+			name: "created",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{createdEvent(t, 1)}
+			},
+			status:  OrderStatusPending,
+			version: 1,
+		},
+		{
+			name: "status changes",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{
+					createdEvent(t, 1),
+					statusEvent(t, 2, OrderStatusPending, OrderStatusPaid),
+					statusEvent(t, 3, OrderStatusPaid, OrderStatusShipped),
+				}
+			},
+			status:  OrderStatusShipped,
+			version: 3,
+		},
+		{
+			name: "sequence gap",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{createdEvent(t, 1), statusEvent(t, 3, OrderStatusPending, OrderStatusPaid)}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+		{
+			name: "sequence repeated",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{createdEvent(t, 1), statusEvent(t, 1, OrderStatusPending, OrderStatusPaid)}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+		{
+			name: "created twice",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{createdEvent(t, 1), createdEvent(t, 2)}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+		{
+			name: "changed before created",
+			events: func(t *testing.T) []OrderEvent {
+				return []OrderEvent{statusEvent(t, 1, OrderStatusPending, OrderStatusPaid)}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+		{
+			name: "unknown type",
+			events: func(t *testing.T) []OrderEvent {
+				unknown := statusEvent(t, 2, OrderStatusPending, OrderStatusPaid)
+				unknown.Type = "order_teleported"
+				return []OrderEvent{createdEvent(t, 1), unknown}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+		{
+			name: "malformed data",
+			events: func(t *testing.T) []OrderEvent {
+				malformed := statusEvent(t, 2, OrderStatusPending, OrderStatusPaid)
+				malformed.Data = []byte(`{"to":`)
+				return []OrderEvent{createdEvent(t, 1), malformed}
+			},
+			wantErr: ErrInvalidOrderEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := &OrderAggregate{}
+			var err error
+			for _, event := range tt.events(t) {
+				if err = aggregate.Apply(event); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if aggregate.Order.Status != tt.status || aggregate.Version != tt.version {
+				t.Errorf("got status %s at version %d, want %s at %d",
+					aggregate.Order.Status, aggregate.Version, tt.status, tt.version)
+			}
+		})
+	}
+}
+
+func TestRehydrateOrder(t *testing.T) {
+	created := createdEvent(t, 1)
+	paid := statusEvent(t, 2, OrderStatusPending, OrderStatusPaid)
+	shipped := statusEvent(t, 3, OrderStatusPaid, OrderStatusShipped)
+
+	t.Run("from events", func(t *testing.T) {
+		aggregate, err := RehydrateOrder(nil, []OrderEvent{created, paid, shipped})
+		if err != nil {
+			t.Fatalf("RehydrateOrder: %v", err)
+		}
+		if aggregate.Order.ID != 7 || aggregate.Order.Status != OrderStatusShipped || aggregate.Version != 3 {
+			t.Errorf("got %+v at version %d", aggregate.Order, aggregate.Version)
+		}
+		if !aggregate.Order.UpdatedAt.Equal(shipped.OccurredAt) {
+			t.Errorf("UpdatedAt = %v, want %v", aggregate.Order.UpdatedAt, shipped.OccurredAt)
+		}
+	})
+
+	t.Run("from snapshot", func(t *testing.T) {
+		snapshot, err := RehydrateOrder(nil, []OrderEvent{created, paid})
+		if err != nil {
+			t.Fatalf("RehydrateOrder: %v", err)
+		}
+
+		aggregate, err := RehydrateOrder(snapshot, []OrderEvent{shipped})
+		if err != nil {
+			t.Fatalf("RehydrateOrder from snapshot: %v", err)
+		}
+		if aggregate.Order.Status != OrderStatusShipped || aggregate.Version != 3 {
+			t.Errorf("got status %s at version %d", aggregate.Order.Status, aggregate.Version)
+		}
+	})
+
+	t.Run("events skipping the snapshot", func(t *testing.T) {
+		snapshot := &OrderAggregate{Order: &Order{ID: 7, Status: OrderStatusPending}, Version: 1}
+		if _, err := RehydrateOrder(snapshot, []OrderEvent{shipped}); !errors.Is(err, ErrInvalidOrderEvent) {
+			t.Errorf("got error %v, want ErrInvalidOrderEvent", err)
+		}
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		if _, err := RehydrateOrder(nil, nil); !errors.Is(err, ErrOrderNotFound) {
+			t.Errorf("got error %v, want ErrOrderNotFound", err)
+		}
+	})
+}
+
+func TestOrderAggregateChangeStatus(t *testing.T) {
+	aggregate, err := RehydrateOrder(nil, []OrderEvent{createdEvent(t, 1)})
+	if err != nil {
+		t.Fatalf("RehydrateOrder: %v", err)
+	}
+
+	event, err := aggregate.ChangeStatus(OrderStatusPaid, eventTime)
+	if err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	if event == nil || event.Sequence != 2 || event.Type != OrderEventStatusChanged {
+		t.Fatalf("got event %+v, want status change numbered 2", event)
+	}
+	if aggregate.Order.Status != OrderStatusPaid || aggregate.Version != 2 {
+		t.Errorf("got status %s at version %d", aggregate.Order.Status, aggregate.Version)
+	}
+
+	event, err = aggregate.ChangeStatus(OrderStatusPaid, eventTime)
+	if err != nil || event != nil {
+		t.Errorf("changing to the current status: got %+v, %v; want no event", event, err)
+	}
+}
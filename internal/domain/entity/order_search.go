@@ -0,0 +1,9 @@
+package entity
+
+// OrderSearchResult is an order matched by a free text search together with
+// its relevance and a highlighted snippet of the matched text.
+type OrderSearchResult struct {
+	Order     Order   `json:"order"`
+	Rank      float32 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrOrderNotPayable   = errors.New("only pending orders can be paid")
+	ErrPaymentInProgress = errors.New("order already has a payment in progress or completed")
+	ErrPaymentDeclined   = errors.New("payment declined")
+	ErrPaymentTimeout    = errors.New("payment gateway timed out")
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "pending"
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	PaymentStatusCaptured   PaymentStatus = "captured"
+	PaymentStatusVoided     PaymentStatus = "voided"
+	// PaymentStatusVoidFailed means the gateway would not release the
+	// authorization; it lapses at the gateway or is voided by hand.
+	PaymentStatusVoidFailed PaymentStatus = "void_failed"
+	PaymentStatusDeclined   PaymentStatus = "declined"
+	PaymentStatusFailed     PaymentStatus = "failed"
+	PaymentStatusRefunded   PaymentStatus = "refunded"
+)
+
+// Payment is an attempt to charge the customer for an order through a
+// payment gateway.
+type Payment struct {
+	ID              int64         `json:"id"`
+	OrderID         int64         `json:"order_id"`
+	Amount          float64       `json:"amount"`
+	Provider        string        `json:"provider"`
+	PaymentMethod   string        `json:"payment_method"`
+	Status          PaymentStatus `json:"status"`
+	AuthorizationID string        `json:"authorization_id,omitempty"`
+	CaptureID       string        `json:"capture_id,omitempty"`
+	FailureReason   string        `json:"failure_reason,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// Active reports whether the payment holds or has taken the customer's money.
+func (p *Payment) Active() bool {
+	switch p.Status {
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured:
+		return true
+	}
+	return false
+}
+
+// CanPay checks that the order is awaiting payment and that no earlier
+// attempt is still running or already succeeded.
+func (o *Order) CanPay(existing []Payment) error {
+	if o.Status != OrderStatusPending {
+		return ErrOrderNotPayable
+	}
+	for i := range existing {
+		if existing[i].Active() {
+			return ErrPaymentInProgress
+		}
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock for one or more items")
 
 type Product struct {
 	ID            int64     `json:"id"`
@@ -8,6 +13,7 @@ type Product struct {
 	Description   string    `json:"description"`
 	Price         float64   `json:"price"`
 	StockQuantity int       `json:"stock_quantity"`
+	WeightGrams   int       `json:"weight_grams"`
 	Category      string    `json:"category"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// UserOrderSummary is one line of a customer's order history.
+type UserOrderSummary struct {
+	OrderID        int64       `json:"order_id"`
+	UserID         int64       `json:"user_id"`
+	Status         OrderStatus `json:"status"`
+	ItemCount      int         `json:"item_count"`
+	LineCount      int         `json:"line_count"`
+	TotalAmount    float64     `json:"total_amount"`
+	ShippingMethod string      `json:"shipping_method"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// DailySales totals the orders placed on a day (UTC) by current status.
+// Revenue is the order total including shipping and taxes.
+type DailySales struct {
+	Day        string      `json:"day"`
+	Status     OrderStatus `json:"status"`
+	OrderCount int         `json:"order_count"`
+	ItemCount  int         `json:"item_count"`
+	Revenue    float64     `json:"revenue"`
+}
+
+// DailyCategorySales totals the items sold per product category. Revenue is
+// the item total before order discounts, shipping and taxes.
+type DailyCategorySales struct {
+	Day        string      `json:"day"`
+	Status     OrderStatus `json:"status"`
+	Category   string      `json:"category"`
+	OrderCount int         `json:"order_count"`
+	Quantity   int         `json:"quantity"`
+	Revenue    float64     `json:"revenue"`
+}
+
+// ProjectionLag tells how far a read model is behind the order events.
+type ProjectionLag struct {
+	Projection    string  `json:"projection"`
+	Position      int64   `json:"position"`
+	EventsBehind  int64   `json:"events_behind"`
+	SecondsBehind float64 `json:"seconds_behind"`
+}
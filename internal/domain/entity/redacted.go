@@ -0,0 +1,65 @@
+package entity
+
+import "go.uber.org/zap/zapcore"
+
+// The Redacted types log an entity without the data that identifies a
+// person, e.g. zap.Object("order", entity.RedactedOrder(*order)). Never log
+// an entity with zap.Any; its addresses and notes would end up in the log.
+
+// RedactedAddress keeps only the country and region of an address.
+type RedactedAddress Address
+
+func (a RedactedAddress) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("country", a.Country)
+	if a.Region != "" {
+		enc.AddString("region", a.Region)
+	}
+	return nil
+}
+
+// RedactedOrder keeps the amounts and state of an order. The user id is left
+// out; the request logger carries it and redacts it where configured.
+type RedactedOrder Order
+
+func (o RedactedOrder) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("id", o.ID)
+	enc.AddString("status", string(o.Status))
+	enc.AddInt("item_count", len(o.Items))
+	enc.AddFloat64("subtotal", o.Subtotal)
+	enc.AddFloat64("discount_amount", o.DiscountAmount)
+	enc.AddFloat64("tax_amount", o.TaxAmount)
+	enc.AddFloat64("shipping_cost", o.ShippingCost)
+	enc.AddFloat64("total_amount", o.TotalAmount)
+	enc.AddString("shipping_method", o.ShippingMethod)
+	return enc.AddObject("shipping_address", RedactedAddress(o.ShippingAddress))
+}
+
+// RedactedPayment leaves out the payment method, which may name the card.
+type RedactedPayment Payment
+
+func (p RedactedPayment) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("id", p.ID)
+	enc.AddInt64("order_id", p.OrderID)
+	enc.AddFloat64("amount", p.Amount)
+	enc.AddString("provider", p.Provider)
+	enc.AddString("status", string(p.Status))
+	if p.FailureReason != "" {
+		enc.AddString("failure_reason", p.FailureReason)
+	}
+	return nil
+}
+
+// RedactedAPIKey never includes the key hash.
+type RedactedAPIKey APIKey
+
+func (k RedactedAPIKey) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("id", k.ID)
+	enc.AddString("name", k.Name)
+	enc.AddString("prefix", k.Prefix)
+	return enc.AddArray("scopes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, scope := range k.Scopes {
+			arr.AppendString(scope)
+		}
+		return nil
+	}))
+}
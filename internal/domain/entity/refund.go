@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrRefundNotFound          = errors.New("refund not found")
+	ErrInvalidRefund           = errors.New("invalid refund")
+	ErrRefundExceedsPayment    = errors.New("refund exceeds the amount left to refund")
+	ErrReturnAlreadyRefunded   = errors.New("return has already been refunded")
+	ErrInvalidRefundTransition = errors.New("refund cannot move to the requested status")
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusCompleted RefundStatus = "completed"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+// Refund is money returned to the customer for an order, optionally for a
+// return. Finance reconciles refunds by their status and reference.
+type Refund struct {
+	ID             int64        `json:"id"`
+	OrderID        int64        `json:"order_id"`
+	ReturnID       *int64       `json:"return_id,omitempty"`
+	Amount         float64      `json:"amount"`
+	ShippingAmount float64      `json:"shipping_amount"`
+	Reason         string       `json:"reason"`
+	Status         RefundStatus `json:"status"`
+	Reference      string       `json:"reference"`
+	CreatedAt      time.Time    `json:"created_at"`
+	CompletedAt    *time.Time   `json:"completed_at,omitempty"`
+}
+
+// NetShippingCost is what the customer paid for shipping after free
+// shipping discounts.
+func (o *Order) NetShippingCost() float64 {
+	cost := o.ShippingCost
+	for _, discount := range o.Discounts {
+		if discount.Type == CouponTypeFreeShipping {
+			cost -= discount.Amount
+		}
+	}
+	return RoundMoney(max(cost, 0))
+}
+
+// ItemRefundAmount is what the customer paid for quantity units of item,
+// including exclusive taxes and net of item discounts spread over the order.
+func (o *Order) ItemRefundAmount(item OrderItem, quantity int) float64 {
+	if item.Quantity == 0 || o.Subtotal == 0 {
+		return 0
+	}
+
+	itemsPaid := o.TotalAmount - o.NetShippingCost()
+	share := item.TotalPrice / o.Subtotal * itemsPaid
+	return RoundMoney(share * float64(quantity) / float64(item.Quantity))
+}
+
+// ValidateRefund checks a new refund against the refunds already recorded,
+// failed ones excluded, so the order is never refunded more than was paid
+// and a return is refunded only once.
+func (o *Order) ValidateRefund(existing []Refund, refund *Refund) error {
+	if refund.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be greater than zero", ErrInvalidRefund)
+	}
+	if refund.ShippingAmount < 0 || refund.ShippingAmount > refund.Amount {
+		return fmt.Errorf("%w: shipping amount must be between zero and the refund amount", ErrInvalidRefund)
+	}
+
+	var refunded, refundedShipping float64
+	for _, r := range existing {
+		if r.Status == RefundStatusFailed {
+			continue
+		}
+		if refund.ReturnID != nil && r.ReturnID != nil && *r.ReturnID == *refund.ReturnID {
+			return fmt.Errorf("%w: return %d", ErrReturnAlreadyRefunded, *refund.ReturnID)
+		}
+		refunded += r.Amount
+		refundedShipping += r.ShippingAmount
+	}
+
+	if RoundMoney(refunded+refund.Amount) > o.TotalAmount {
+		return fmt.Errorf("%w: %.2f already refunded of %.2f", ErrRefundExceedsPayment, refunded, o.TotalAmount)
+	}
+	if refund.ShippingAmount > 0 && RoundMoney(refundedShipping+refund.ShippingAmount) > o.NetShippingCost() {
+		return fmt.Errorf("%w: shipping refund exceeds the shipping paid", ErrRefundExceedsPayment)
+	}
+
+	return nil
+}
+
+// TransitionTo settles a pending refund as completed or failed.
+func (r *Refund) TransitionTo(status RefundStatus, at time.Time) error {
+	if r.Status != RefundStatusPending || (status != RefundStatusCompleted && status != RefundStatusFailed) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidRefundTransition, r.Status, status)
+	}
+
+	r.Status = status
+	if status == RefundStatusCompleted {
+		r.CompletedAt = &at
+	}
+	return nil
+}
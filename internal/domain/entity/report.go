@@ -0,0 +1,87 @@
+package entity
+
+import "time"
+
+type ReportInterval string
+
+const (
+	ReportIntervalDay   ReportInterval = "day"
+	ReportIntervalWeek  ReportInterval = "week"
+	ReportIntervalMonth ReportInterval = "month"
+)
+
+func (i ReportInterval) Valid() bool {
+	switch i {
+	case ReportIntervalDay, ReportIntervalWeek, ReportIntervalMonth:
+		return true
+	}
+	return false
+}
+
+// ReportRange selects the orders placed from From up to, but excluding, To.
+// Location is the timezone buckets are aligned to.
+type ReportRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// RevenueBucket totals the orders placed in one day, week (starting Monday)
+// or month. Cancelled orders are left out.
+type RevenueBucket struct {
+	PeriodStart       string  `json:"period_start"`
+	OrderCount        int64   `json:"order_count"`
+	Revenue           float64 `json:"revenue"`
+	AverageOrderValue float64 `json:"average_order_value"`
+}
+
+type TopProductsOrder string
+
+const (
+	TopProductsByQuantity TopProductsOrder = "quantity"
+	TopProductsByRevenue  TopProductsOrder = "revenue"
+)
+
+// TopProduct totals the items of one product sold in non-cancelled orders.
+// Revenue is the item total before order discounts.
+type TopProduct struct {
+	ProductID  int64   `json:"product_id"`
+	Name       string  `json:"name"`
+	Category   string  `json:"category"`
+	Quantity   int64   `json:"quantity"`
+	Revenue    float64 `json:"revenue"`
+	OrderCount int64   `json:"order_count"`
+}
+
+// StatusFunnel counts how far the orders placed in a range got. An order
+// counts towards every stage it ever reached, so cancelling a paid order
+// still counts it as paid.
+type StatusFunnel struct {
+	Placed           int64                 `json:"placed"`
+	Paid             int64                 `json:"paid"`
+	Shipped          int64                 `json:"shipped"`
+	Delivered        int64                 `json:"delivered"`
+	Cancelled        int64                 `json:"cancelled"`
+	ByStatus         map[OrderStatus]int64 `json:"by_status"`
+	PaymentRate      float64               `json:"payment_rate"`
+	ShipmentRate     float64               `json:"shipment_rate"`
+	DeliveryRate     float64               `json:"delivery_rate"`
+	CancellationRate float64               `json:"cancellation_rate"`
+}
+
+// ComputeRates derives the conversion rates from the stage counts. Each rate
+// is relative to the previous stage; the cancellation rate is relative to all
+// placed orders.
+func (f *StatusFunnel) ComputeRates() {
+	f.PaymentRate = ratio(f.Paid, f.Placed)
+	f.ShipmentRate = ratio(f.Shipped, f.Paid)
+	f.DeliveryRate = ratio(f.Delivered, f.Shipped)
+	f.CancellationRate = ratio(f.Cancelled, f.Placed)
+}
+
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
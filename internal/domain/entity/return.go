@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrReturnNotFound          = errors.New("return not found")
+	ErrInvalidReturn           = errors.New("invalid return")
+	ErrOrderNotReturnable      = errors.New("only delivered orders can be returned")
+	ErrInvalidReturnTransition = errors.New("return cannot move to the requested status")
+)
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusReceived  ReturnStatus = "received"
+)
+
+type ReturnReason string
+
+const (
+	ReturnReasonDamaged        ReturnReason = "damaged"
+	ReturnReasonWrongItem      ReturnReason = "wrong_item"
+	ReturnReasonNotAsDescribed ReturnReason = "not_as_described"
+	ReturnReasonNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReturnReasonOther          ReturnReason = "other"
+)
+
+// ReturnRequest is a customer's request to send back items of an order (RMA).
+type ReturnRequest struct {
+	ID             int64        `json:"id"`
+	OrderID        int64        `json:"order_id"`
+	UserID         int64        `json:"user_id"`
+	Status         ReturnStatus `json:"status"`
+	Comment        string       `json:"comment"`
+	ResolutionNote string       `json:"resolution_note"`
+	Items          []ReturnItem `json:"items"`
+	Restocked      bool         `json:"restocked"`
+	ReceivedAt     *time.Time   `json:"received_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type ReturnItem struct {
+	ID          int64        `json:"id"`
+	ReturnID    int64        `json:"return_id"`
+	OrderItemID int64        `json:"order_item_id"`
+	Quantity    int          `json:"quantity"`
+	Reason      ReturnReason `json:"reason"`
+}
+
+func (r ReturnReason) valid() bool {
+	switch r {
+	case ReturnReasonDamaged, ReturnReasonWrongItem, ReturnReasonNotAsDescribed,
+		ReturnReasonNoLongerNeeded, ReturnReasonOther:
+		return true
+	}
+	return false
+}
+
+// ValidateReturn checks that a return request only contains delivered items
+// of the order and does not return more than was ordered, counting earlier
+// returns that were not rejected. Errors wrap ErrInvalidReturn.
+func (o *Order) ValidateReturn(existing []ReturnRequest, ret *ReturnRequest) error {
+	if o.Status != OrderStatusDelivered {
+		return ErrOrderNotReturnable
+	}
+	if len(ret.Items) == 0 {
+		return fmt.Errorf("%w: at least one item is required", ErrInvalidReturn)
+	}
+
+	remaining := make(map[int64]int, len(o.Items))
+	for _, item := range o.Items {
+		remaining[item.ID] += item.Quantity
+	}
+	for _, r := range existing {
+		if r.Status == ReturnStatusRejected {
+			continue
+		}
+		for _, item := range r.Items {
+			remaining[item.OrderItemID] -= item.Quantity
+		}
+	}
+
+	for _, item := range ret.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidReturn)
+		}
+		if !item.Reason.valid() {
+			return fmt.Errorf("%w: unknown reason %q", ErrInvalidReturn, item.Reason)
+		}
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return fmt.Errorf("%w: order item %d does not belong to order %d", ErrInvalidReturn, item.OrderItemID, o.ID)
+		}
+		if item.Quantity > left {
+			return fmt.Errorf("%w: only %d of order item %d can be returned", ErrInvalidReturn, left, item.OrderItemID)
+		}
+		remaining[item.OrderItemID] = left - item.Quantity
+	}
+
+	return nil
+}
+
+// TransitionTo moves the return to status if the workflow allows it:
+// requested returns are approved or rejected, approved returns are received.
+func (r *ReturnRequest) TransitionTo(status ReturnStatus) error {
+	allowed := false
+	switch r.Status {
+	case ReturnStatusRequested:
+		allowed = status == ReturnStatusApproved || status == ReturnStatusRejected
+	case ReturnStatusApproved:
+		allowed = status == ReturnStatusReceived
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidReturnTransition, r.Status, status)
+	}
+
+	r.Status = status
+	r.UpdatedAt = time.Now()
+	return nil
+}
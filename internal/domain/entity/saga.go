@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSagaInProgress = errors.New("a saga for this key is already in progress")
+	ErrSagaTimeout    = errors.New("saga timed out")
+)
+
+type SagaStatus string
+
+const (
+	SagaStatusRunning      SagaStatus = "running"
+	SagaStatusCompensating SagaStatus = "compensating"
+	SagaStatusCompleted    SagaStatus = "completed"
+	SagaStatusCompensated  SagaStatus = "compensated"
+	// SagaStatusFailed means a compensation failed and the saga needs
+	// manual attention.
+	SagaStatusFailed SagaStatus = "failed"
+)
+
+// Saga is the persisted state of a long running, multi-step operation.
+// While running, Step is the index of the next step to execute; while
+// compensating, it is the number of steps still to be compensated.
+type Saga struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	Key       string          `json:"key"`
+	Status    SagaStatus      `json:"status"`
+	Step      int             `json:"step"`
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error,omitempty"`
+	Deadline  time.Time       `json:"deadline"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Finished reports whether the saga has nothing left to do.
+func (s *Saga) Finished() bool {
+	return s.Status == SagaStatusCompleted || s.Status == SagaStatusCompensated || s.Status == SagaStatusFailed
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrShipmentNotFound  = errors.New("shipment not found")
+	ErrInvalidShipment   = errors.New("invalid shipment")
+	ErrOrderNotShippable = errors.New("order cannot be shipped in its current status")
+	ErrShipmentDelivered = errors.New("shipment already delivered")
+)
+
+type ShipmentStatus string
+
+const (
+	ShipmentStatusShipped   ShipmentStatus = "shipped"
+	ShipmentStatusDelivered ShipmentStatus = "delivered"
+)
+
+// Shipment is a parcel sent for part or all of an order.
+type Shipment struct {
+	ID             int64          `json:"id"`
+	OrderID        int64          `json:"order_id"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Status         ShipmentStatus `json:"status"`
+	Items          []ShipmentItem `json:"items"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type ShipmentItem struct {
+	ID          int64 `json:"id"`
+	ShipmentID  int64 `json:"shipment_id"`
+	OrderItemID int64 `json:"order_item_id"`
+	Quantity    int   `json:"quantity"`
+}
+
+// CanShip reports whether new shipments may be created for the order.
+func (o *Order) CanShip() bool {
+	return o.Status == OrderStatusPaid || o.Status == OrderStatusPartiallyShipped
+}
+
+// ValidateShipment checks that a new shipment only contains items of the
+// order and does not ship more than was ordered, taking earlier shipments
+// into account. Errors wrap ErrInvalidShipment.
+func (o *Order) ValidateShipment(existing []Shipment, shipment *Shipment) error {
+	if !o.CanShip() {
+		return ErrOrderNotShippable
+	}
+	if strings.TrimSpace(shipment.Carrier) == "" {
+		return fmt.Errorf("%w: carrier is required", ErrInvalidShipment)
+	}
+	if strings.TrimSpace(shipment.TrackingNumber) == "" {
+		return fmt.Errorf("%w: tracking number is required", ErrInvalidShipment)
+	}
+	if len(shipment.Items) == 0 {
+		return fmt.Errorf("%w: at least one item is required", ErrInvalidShipment)
+	}
+
+	remaining := o.unshippedQuantities(existing)
+	for _, item := range shipment.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidShipment)
+		}
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return fmt.Errorf("%w: order item %d does not belong to order %d", ErrInvalidShipment, item.OrderItemID, o.ID)
+		}
+		if item.Quantity > left {
+			return fmt.Errorf("%w: only %d of order item %d left to ship", ErrInvalidShipment, left, item.OrderItemID)
+		}
+		remaining[item.OrderItemID] = left - item.Quantity
+	}
+
+	return nil
+}
+
+// FulfillmentStatus derives the order status from its shipments: partially
+// shipped while items are still missing, shipped once every item is in a
+// shipment and delivered once all of those shipments have been delivered.
+func (o *Order) FulfillmentStatus(shipments []Shipment) OrderStatus {
+	if len(shipments) == 0 {
+		return o.Status
+	}
+
+	for _, left := range o.unshippedQuantities(shipments) {
+		if left > 0 {
+			return OrderStatusPartiallyShipped
+		}
+	}
+
+	for _, shipment := range shipments {
+		if shipment.Status != ShipmentStatusDelivered {
+			return OrderStatusShipped
+		}
+	}
+
+	return OrderStatusDelivered
+}
+
+func (o *Order) unshippedQuantities(shipments []Shipment) map[int64]int {
+	remaining := make(map[int64]int, len(o.Items))
+	for _, item := range o.Items {
+		remaining[item.ID] += item.Quantity
+	}
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			remaining[item.OrderItemID] -= item.Quantity
+		}
+	}
+	return remaining
+}
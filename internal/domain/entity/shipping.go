@@ -0,0 +1,17 @@
+package entity
+
+import "errors"
+
+var (
+	ErrNoShippingAvailable       = errors.New("no shipping method available for this destination")
+	ErrShippingMethodUnavailable = errors.New("shipping method not available for this order")
+)
+
+// ShippingQuote is the price of shipping a cart with one shipping method.
+type ShippingQuote struct {
+	Method        string  `json:"method"`
+	Carrier       string  `json:"carrier"`
+	Name          string  `json:"name"`
+	Cost          float64 `json:"cost"`
+	EstimatedDays int     `json:"estimated_days"`
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "sort"
+
+// TaxSummaryLine aggregates the order lines taxed at the same rate.
+type TaxSummaryLine struct {
+	Rate          float64 `json:"rate"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// ItemDiscount returns the part of discounts that lowers the price of the
+// items. Free shipping discounts lower the shipping cost instead.
+func ItemDiscount(discounts []OrderDiscount) float64 {
+	var amount float64
+	for _, discount := range discounts {
+		if discount.Type != CouponTypeFreeShipping {
+			amount += discount.Amount
+		}
+	}
+	return RoundMoney(amount)
+}
+
+// DiscountedLineAmount returns the total of an order line after the item
+// discount of the order was spread over the lines in proportion to their
+// share of subtotal. Taxes are computed on this amount.
+func DiscountedLineAmount(lineTotal, itemDiscount, subtotal float64) float64 {
+	amount := lineTotal
+	if itemDiscount > 0 && subtotal > 0 {
+		amount -= itemDiscount * lineTotal / subtotal
+	}
+	return RoundMoney(amount)
+}
+
+// BuildTaxSummary groups the taxes of the order items by rate. The taxable
+// amount of each line is its total after the item discount, see
+// DiscountedLineAmount.
+func BuildTaxSummary(items []OrderItem, itemDiscount, subtotal float64) []TaxSummaryLine {
+	byRate := make(map[float64]*TaxSummaryLine)
+	for _, item := range items {
+		line, ok := byRate[item.TaxRate]
+		if !ok {
+			line = &TaxSummaryLine{Rate: item.TaxRate}
+			byRate[item.TaxRate] = line
+		}
+		line.TaxableAmount += DiscountedLineAmount(item.TotalPrice, itemDiscount, subtotal)
+		line.TaxAmount += item.TaxAmount
+	}
+
+	summary := make([]TaxSummaryLine, 0, len(byRate))
+	for _, line := range byRate {
+		line.TaxableAmount = RoundMoney(line.TaxableAmount)
+		line.TaxAmount = RoundMoney(line.TaxAmount)
+		summary = append(summary, *line)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].Rate > summary[j].Rate
+	})
+
+	return summary
+}
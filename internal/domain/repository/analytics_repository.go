@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+// AnalyticsRepository aggregates the orders tables directly, so its figures
+// are always current but cost more than the read models.
+type AnalyticsRepository interface {
+	Revenue(ctx context.Context, r entity.ReportRange, interval entity.ReportInterval) ([]entity.RevenueBucket, error)
+	TopProducts(ctx context.Context, r entity.ReportRange, by entity.TopProductsOrder, limit int) ([]entity.TopProduct, error)
+	StatusFunnel(ctx context.Context, r entity.ReportRange) (*entity.StatusFunnel, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"time"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) error
+	GetAPIKey(ctx context.Context, keyID int64) (*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	// RotateAPIKey stores replacement and makes the old key stop working at
+	// retireAt, in one transaction.
+	RotateAPIKey(ctx context.Context, keyID int64, replacement *entity.APIKey, retireAt time.Time) error
+	RevokeAPIKey(ctx context.Context, keyID int64, at time.Time) error
+	// TouchAPIKey records a use of the key. Uses within a minute of the
+	// previous one are not written.
+	TouchAPIKey(ctx context.Context, keyID int64, at time.Time) error
+}
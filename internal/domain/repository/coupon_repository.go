@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+type CouponRepository interface {
+	CreateCoupon(ctx context.Context, coupon *entity.Coupon) error
+	UpdateCoupon(ctx context.Context, coupon *entity.Coupon) error
+	GetCouponByID(ctx context.Context, couponID int64) (*entity.Coupon, error)
+	GetCouponByCode(ctx context.Context, code string) (*entity.Coupon, error)
+	ListCoupons(ctx context.Context, limit, offset int) ([]entity.Coupon, int64, error)
+	CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+)
+
+type InventoryRepository interface {
+	// ReserveStock takes the items of an order out of stock. Reserving an
+	// already reserved order does nothing.
+	ReserveStock(ctx context.Context, orderID int64) error
+	// ReleaseStock puts reserved items back in stock. Releasing an order
+	// without a reservation does nothing.
+	ReleaseStock(ctx context.Context, orderID int64) error
+}
@@ -17,4 +17,8 @@ import (
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *entity.Order) error
 	GetOrderByID(ctx context.Context, orderID int64) (*entity.Order, error)
+	SearchOrders(ctx context.Context, query string, limit, offset int) ([]entity.OrderSearchResult, int64, error)
+	// ListOrdersByUser returns a page of the user's orders, newest first, and
+	// the total number of orders the user has.
+	ListOrdersByUser(ctx context.Context, userID int64, limit, offset int) ([]entity.UserOrderSummary, int64, error)
 }
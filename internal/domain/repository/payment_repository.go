@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+type PaymentRepository interface {
+	// CreatePayment stores a pending payment after checking with the order
+	// locked that the order can be paid.
+	CreatePayment(ctx context.Context, payment *entity.Payment) error
+	// UpdatePayment saves the gateway outcome of a payment.
+	UpdatePayment(ctx context.Context, payment *entity.Payment) error
+	// CompletePayment saves a captured payment and marks the order paid in
+	// one transaction. It fails with entity.ErrOrderNotPayable when the
+	// order left the pending status in the meantime.
+	CompletePayment(ctx context.Context, payment *entity.Payment) error
+	ListPayments(ctx context.Context, orderID int64) ([]entity.Payment, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+type ProductRepository interface {
+	GetProductsByIDs(ctx context.Context, productIDs []int64) ([]entity.Product, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"time"
+)
+
+// The read model repositories serve queries from denormalized tables that
+// trail the order events by a few seconds.
+
+type OrderHistoryRepository interface {
+	ListUserOrders(ctx context.Context, userID int64, limit, offset int) ([]entity.UserOrderSummary, int64, error)
+}
+
+type SalesReportRepository interface {
+	// DailySales returns the totals of the days from from to to, inclusive.
+	DailySales(ctx context.Context, from, to time.Time) ([]entity.DailySales, error)
+	DailyCategorySales(ctx context.Context, from, to time.Time) ([]entity.DailyCategorySales, error)
+}
+
+type ProjectionRepository interface {
+	ProjectionLag(ctx context.Context) ([]entity.ProjectionLag, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+type ReturnRepository interface {
+	// CreateReturn validates and stores a return request with the order
+	// locked, so concurrent requests cannot return more than was ordered.
+	CreateReturn(ctx context.Context, ret *entity.ReturnRequest) error
+	GetReturn(ctx context.Context, returnID int64) (*entity.ReturnRequest, error)
+	ListReturns(ctx context.Context, orderID int64) ([]entity.ReturnRequest, error)
+	// UpdateReturnStatus saves a status change made with TransitionTo. It
+	// fails with entity.ErrInvalidReturnTransition when the stored status is
+	// no longer from. With restock the returned quantities are put back in
+	// stock in the same transaction.
+	UpdateReturnStatus(ctx context.Context, ret *entity.ReturnRequest, from entity.ReturnStatus, restock bool) error
+}
+
+type RefundRepository interface {
+	// CreateRefund validates and stores a refund with the order locked, so
+	// concurrent refunds cannot exceed what the customer paid.
+	CreateRefund(ctx context.Context, refund *entity.Refund) error
+	GetRefund(ctx context.Context, refundID int64) (*entity.Refund, error)
+	ListRefunds(ctx context.Context, orderID int64) ([]entity.Refund, error)
+	// UpdateRefundStatus saves a status change made with TransitionTo. It
+	// fails with entity.ErrInvalidRefundTransition when the stored status is
+	// no longer from.
+	UpdateRefundStatus(ctx context.Context, refund *entity.Refund, from entity.RefundStatus) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"time"
+)
+
+type SagaRepository interface {
+	// CreateSaga stores a new saga. It fails with entity.ErrSagaInProgress
+	// when an unfinished saga with the same name and key exists.
+	CreateSaga(ctx context.Context, saga *entity.Saga) error
+	SaveSaga(ctx context.Context, saga *entity.Saga) error
+	// ListUnfinishedSagas returns running and compensating sagas last saved
+	// before updatedBefore.
+	ListUnfinishedSagas(ctx context.Context, updatedBefore time.Time) ([]entity.Saga, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"time"
+)
+
+type ShipmentRepository interface {
+	// CreateShipment validates and stores a shipment with the order locked, so
+	// concurrent shipments cannot ship more than was ordered, and moves the
+	// order to the status derived from all of its shipments.
+	CreateShipment(ctx context.Context, shipment *entity.Shipment) (entity.OrderStatus, error)
+	ListShipments(ctx context.Context, orderID int64) ([]entity.Shipment, error)
+	// MarkShipmentDelivered confirms delivery of a shipment and updates the
+	// derived order status in the same transaction.
+	MarkShipmentDelivered(ctx context.Context, orderID, shipmentID int64, deliveredAt time.Time) (*entity.Shipment, entity.OrderStatus, error)
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+)
+
+type AuthorizeRequest struct {
+	OrderID       int64
+	Amount        float64
+	PaymentMethod string
+	// IdempotencyKey lets the gateway recognise retries of the same request.
+	IdempotencyKey string
+}
+
+// PaymentGateway moves money through a payment provider. Declines wrap
+// entity.ErrPaymentDeclined and timeouts wrap entity.ErrPaymentTimeout.
+type PaymentGateway interface {
+	// Name identifies the provider in stored payments.
+	Name() string
+	// Authorize reserves the amount on the customer's payment method and
+	// returns the authorization reference.
+	Authorize(ctx context.Context, req AuthorizeRequest) (string, error)
+	// Capture takes the authorized amount and returns the capture reference.
+	Capture(ctx context.Context, authorizationID string, amount float64) (string, error)
+	// Void releases an authorization that was not captured.
+	Void(ctx context.Context, authorizationID string) error
+	// Refund returns captured money and returns the refund reference.
+	Refund(ctx context.Context, captureID string, amount float64) (string, error)
+}
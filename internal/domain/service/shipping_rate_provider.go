@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+type ShippingItem struct {
+	ProductID   int64
+	Quantity    int
+	WeightGrams int
+	Value       float64
+}
+
+type ShippingQuoteRequest struct {
+	Destination entity.Address
+	Items       []ShippingItem
+}
+
+func (r ShippingQuoteRequest) TotalWeightGrams() int {
+	var total int
+	for _, item := range r.Items {
+		total += item.WeightGrams * item.Quantity
+	}
+	return total
+}
+
+func (r ShippingQuoteRequest) TotalValue() float64 {
+	var total float64
+	for _, item := range r.Items {
+		total += item.Value
+	}
+	return entity.RoundMoney(total)
+}
+
+// ShippingRateProvider quotes the available shipping methods for a cart,
+// cheapest first. It returns entity.ErrNoShippingAvailable when the
+// destination is not served.
+type ShippingRateProvider interface {
+	Quote(ctx context.Context, req ShippingQuoteRequest) ([]entity.ShippingQuote, error)
+}
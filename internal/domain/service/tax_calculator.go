@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+)
+
+// TaxableLine is one order line to be taxed. Amount is the line total after
+// order-level discounts have been allocated to it.
+type TaxableLine struct {
+	ProductID int64
+	Category  string
+	Amount    float64
+}
+
+type TaxRequest struct {
+	Destination entity.Address
+	Lines       []TaxableLine
+}
+
+// LineTax is the tax computed for the TaxableLine at the same index.
+type LineTax struct {
+	Rate      float64
+	TaxAmount float64
+}
+
+type TaxResult struct {
+	Jurisdiction     string
+	PricesIncludeTax bool
+	Lines            []LineTax
+	TotalTax         float64
+}
+
+// TaxCalculator computes the taxes of an order. The built-in implementation
+// is table driven; external tax providers can be plugged in behind the same
+// interface.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req TaxRequest) (*TaxResult, error)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config describes which bearer tokens the service accepts. The HS256
+// secret is not part of the file; it comes from the environment.
+type Config struct {
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+	// Algorithms lists the accepted signing algorithms: HS256, RS256 and
+	// ES256. HS256 needs a secret and the others a JWKS source.
+	Algorithms    []string   `json:"algorithms"`
+	LeewaySeconds int        `json:"leeway_seconds"`
+	RolesClaim    string     `json:"roles_claim"`
+	JWKS          JWKSConfig `json:"jwks"`
+}
+
+// JWKSConfig locates the public keys of the token issuer. URL takes
+// precedence over File.
+type JWKSConfig struct {
+	URL  string `json:"url"`
+	File string `json:"file"`
+	// CacheTTLSeconds is how long fetched keys are used before fetching
+	// them again.
+	CacheTTLSeconds int `json:"cache_ttl_seconds"`
+	// MinRefreshIntervalSeconds is the least time between two fetches,
+	// expired or not, so bogus key ids cannot hammer the issuer.
+	MinRefreshIntervalSeconds int `json:"min_refresh_interval_seconds"`
+}
+
+// LoadConfig reads Config from a JSON file.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal auth config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c Config) leeway() time.Duration {
+	return time.Duration(c.LeewaySeconds) * time.Second
+}
+
+func (c JWKSConfig) enabled() bool {
+	return c.URL != "" || c.File != ""
+}
+
+func (c JWKSConfig) cacheTTL() time.Duration {
+	if c.CacheTTLSeconds <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.CacheTTLSeconds) * time.Second
+}
+
+func (c JWKSConfig) minRefreshInterval() time.Duration {
+	if c.MinRefreshIntervalSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.MinRefreshIntervalSeconds) * time.Second
+}
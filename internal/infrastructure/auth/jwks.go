@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"go.uber.org/zap"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwk is one entry of a JSON Web Key Set (RFC 7517). Only the members of
+// RSA and EC public keys are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the public keys of a JWKS document. Keys are fetched again
+// when the cache expires or a token names a key id that is not cached, which
+// picks up rotated keys without a restart. Fetches are at least minRefresh
+// apart and one at a time; expired keys are served while the next fetch runs.
+type keySet struct {
+	fetch      func(ctx context.Context) ([]byte, error)
+	ttl        time.Duration
+	minRefresh time.Duration
+	now        func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// refreshing is closed when the fetch in flight ends; nil when none is
+	refreshing chan struct{}
+	// refreshErr is the error of the last fetch, returned while no keys
+	// were ever fetched
+	refreshErr error
+}
+
+func newKeySet(cfg JWKSConfig) *keySet {
+	ks := &keySet{
+		ttl:        cfg.cacheTTL(),
+		minRefresh: cfg.minRefreshInterval(),
+		now:        time.Now,
+	}
+	if cfg.URL != "" {
+		client := &http.Client{Timeout: 5 * time.Second}
+		ks.fetch = func(ctx context.Context) ([]byte, error) {
+			return fetchURL(ctx, client, cfg.URL)
+		}
+	} else {
+		ks.fetch = func(context.Context) ([]byte, error) {
+			return os.ReadFile(cfg.File)
+		}
+	}
+	return ks
+}
+
+// Key returns the public key with the given key id. A key that is cached
+// is returned at once, even when expired; otherwise Key waits for the fetch
+// in flight, or starts one unless the last attempt was too recent.
+func (ks *keySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	now := ks.now()
+	key, found := ks.keys[kid]
+	if found && now.Sub(ks.fetchedAt) < ks.ttl {
+		ks.mu.Unlock()
+		return key, nil
+	}
+
+	done := ks.refreshing
+	if done == nil && now.Sub(ks.attemptedAt) >= ks.minRefresh {
+		ks.attemptedAt = now
+		done = make(chan struct{})
+		ks.refreshing = done
+		// The fetch outlives the request that started it
+		go ks.refresh(context.WithoutCancel(ctx), done)
+	}
+	ks.mu.Unlock()
+
+	if found {
+		return key, nil
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if key, found := ks.keys[kid]; found {
+		return key, nil
+	}
+	if ks.keys == nil && ks.refreshErr != nil {
+		return nil, ks.refreshErr
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// refresh fetches the keys and closes done once they are stored.
+func (ks *keySet) refresh(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	keys, err := ks.load(ctx)
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.refreshing = nil
+	ks.refreshErr = err
+	if err != nil {
+		// Keep serving the cached keys while the issuer is unreachable
+		logger.Warn("Failed to refresh JWKS", zap.Error(err))
+		return
+	}
+	ks.keys = keys
+	ks.fetchedAt = ks.now()
+}
+
+func (ks *keySet) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := ks.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(data)
+}
+
+// parseJWKS decodes the signing keys of a JWKS document. Keys of other types
+// or uses are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func fetchURL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	testKeysOnce sync.Once
+	testRSAKey   *rsa.PrivateKey
+	testECKey    *ecdsa.PrivateKey
+)
+
+// testKeys returns an RSA and an EC key pair, generated once per run.
+func testKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	testKeysOnce.Do(func() {
+		var err error
+		if testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatalf("generate RSA key: %v", err)
+		}
+		if testECKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			t.Fatalf("generate EC key: %v", err)
+		}
+	})
+	return testRSAKey, testECKey
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwksDocument encodes public keys by key id as a JWKS document.
+func jwksDocument(t *testing.T, keys map[string]crypto.PublicKey) []byte {
+	t.Helper()
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			doc.Keys = append(doc.Keys, jwk{Kty: "RSA", Kid: kid, Use: "sig",
+				N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())})
+		case *ecdsa.PublicKey:
+			doc.Keys = append(doc.Keys, jwk{Kty: "EC", Kid: kid, Crv: "P-256",
+				X: b64(k.X.FillBytes(make([]byte, 32))), Y: b64(k.Y.FillBytes(make([]byte, 32)))})
+		default:
+			t.Fatalf("unsupported key type %T", key)
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, ecKey := testKeys(t)
+	valid := jwksDocument(t, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey})
+
+	tests := []struct {
+		name    string
+		data    string
+		kids    []string
+		wantErr bool
+	}{
+		{name: "RSA and EC keys", data: string(valid), kids: []string{"ec", "rsa"}},
+		{name: "encryption key skipped", data: `{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}]}`},
+		{name: "symmetric key skipped", data: `{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`},
+		{name: "no keys", data: `{"keys":[]}`},
+		{name: "invalid JSON", data: `{"keys":`, wantErr: true},
+		{name: "invalid base64", data: `{"keys":[{"kty":"RSA","kid":"a","n":"!!","e":"AQAB"}]}`, wantErr: true},
+		{name: "exponent too small", data: `{"keys":[{"kty":"RSA","kid":"a","n":"AQAB","e":"AQ"}]}`, wantErr: true},
+		{name: "unsupported curve", data: `{"keys":[{"kty":"EC","kid":"a","crv":"P-192","x":"AQ","y":"AQ"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJWKS: %v", err)
+			}
+			if len(keys) != len(tt.kids) {
+				t.Fatalf("got %d keys, want %v", len(keys), tt.kids)
+			}
+			for _, kid := range tt.kids {
+				if _, ok := keys[kid]; !ok {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+
+	keys, _ := parseJWKS(valid)
+	if !rsaKey.PublicKey.Equal(keys["rsa"]) || !ecKey.PublicKey.Equal(keys["ec"]) {
+		t.Error("parsed keys differ from the encoded ones")
+	}
+}
+
+// fakeIssuer serves JWKS documents to a keySet on a fake clock.
+type fakeIssuer struct {
+	mu      sync.Mutex
+	doc     []byte
+	err     error
+	fetches int
+	// release, when set, holds every fetch until it is closed
+	release chan struct{}
+}
+
+func (f *fakeIssuer) fetch(context.Context) ([]byte, error) {
+	f.mu.Lock()
+	f.fetches++
+	release := f.release
+	f.mu.Unlock()
+
+	if release != nil {
+		<-release
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.doc, f.err
+}
+
+func (f *fakeIssuer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches
+}
+
+func newTestKeySet(issuer *fakeIssuer, now *time.Time) *keySet {
+	return &keySet{
+		fetch:      issuer.fetch,
+		ttl:        10 * time.Minute,
+		minRefresh: 30 * time.Second,
+		now:        func() time.Time { return *now },
+	}
+}
+
+// waitRefresh waits for the fetch in flight, if any.
+func waitRefresh(ks *keySet) {
+	ks.mu.Lock()
+	done := ks.refreshing
+	ks.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	rsaKey, ecKey := testKeys(t)
+	now := time.Unix(1_700_000_000, 0)
+	issuer := &fakeIssuer{doc: jwksDocument(t, map[string]crypto.PublicKey{"old": &rsaKey.PublicKey})}
+	ks := newTestKeySet(issuer, &now)
+	ctx := context.Background()
+
+	if _, err := ks.Key(ctx, "old"); err != nil {
+		t.Fatalf("first key: %v", err)
+	}
+
+	// The issuer rotates; the new key id is fetched, but not more often
+	// than minRefresh
+	issuer.mu.Lock()
+	issuer.doc = jwksDocument(t, map[string]crypto.PublicKey{"old": &rsaKey.PublicKey, "new": &ecKey.PublicKey})
+	issuer.mu.Unlock()
+
+	now = now.Add(10 * time.Second)
+	if _, err := ks.Key(ctx, "new"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got error %v before minRefresh passed, want ErrUnknownKey", err)
+	}
+	if issuer.count() != 1 {
+		t.Fatalf("fetched %d times before minRefresh passed, want 1", issuer.count())
+	}
+
+	now = now.Add(30 * time.Second)
+	key, err := ks.Key(ctx, "new")
+	if err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if !ecKey.PublicKey.Equal(key) {
+		t.Error("got another key for the rotated key id")
+	}
+	if _, err := ks.Key(ctx, "bogus"); !errors.Is(err, ErrUnknownKey) || issuer.count() != 2 {
+		t.Errorf("bogus key id: got error %v after %d fetches, want ErrUnknownKey after 2", err, issuer.count())
+	}
+}
+
+func TestKeySetServesExpiredKeysWhileFetching(t *testing.T) {
+	rsaKey, _ := testKeys(t)
+	now := time.Unix(1_700_000_000, 0)
+	issuer := &fakeIssuer{doc: jwksDocument(t, map[string]crypto.PublicKey{"a": &rsaKey.PublicKey})}
+	ks := newTestKeySet(issuer, &now)
+	ctx := context.Background()
+
+	if _, err := ks.Key(ctx, "a"); err != nil {
+		t.Fatalf("first key: %v", err)
+	}
+
+	issuer.mu.Lock()
+	issuer.release = make(chan struct{})
+	issuer.mu.Unlock()
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := ks.Key(ctx, "a"); err != nil {
+			t.Fatalf("expired key %d: %v", i, err)
+		}
+	}
+
+	close(issuer.release)
+	waitRefresh(ks)
+	if issuer.count() != 2 {
+		t.Errorf("fetched %d times, want 2: one fetch for all requests of the expired key", issuer.count())
+	}
+	if !ks.fetchedAt.Equal(now) {
+		t.Errorf("fetchedAt = %v, want %v", ks.fetchedAt, now)
+	}
+}
+
+func TestKeySetFetchFailure(t *testing.T) {
+	logs, restore := logger.ObserveGlobal(zapcore.WarnLevel)
+	defer restore()
+
+	rsaKey, _ := testKeys(t)
+	now := time.Unix(1_700_000_000, 0)
+	issuer := &fakeIssuer{err: errors.New("issuer down")}
+	ks := newTestKeySet(issuer, &now)
+	ctx := context.Background()
+
+	if _, err := ks.Key(ctx, "a"); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got error %v without any keys, want the fetch error", err)
+	}
+
+	issuer.mu.Lock()
+	issuer.doc, issuer.err = jwksDocument(t, map[string]crypto.PublicKey{"a": &rsaKey.PublicKey}), nil
+	issuer.mu.Unlock()
+	now = now.Add(30 * time.Second)
+	if _, err := ks.Key(ctx, "a"); err != nil {
+		t.Fatalf("after the issuer recovered: %v", err)
+	}
+
+	// Once expired, the cached keys are kept while the issuer is down
+	issuer.mu.Lock()
+	issuer.err = errors.New("issuer down")
+	issuer.mu.Unlock()
+	now = now.Add(time.Hour)
+	if _, err := ks.Key(ctx, "a"); err != nil {
+		t.Fatalf("expired key: %v", err)
+	}
+	waitRefresh(ks)
+	if _, err := ks.Key(ctx, "a"); err != nil {
+		t.Fatalf("expired key after a failed fetch: %v", err)
+	}
+	if logs.FilterMessage("Failed to refresh JWKS").Len() != 2 {
+		t.Errorf("logged %d failed fetches, want 2", logs.FilterMessage("Failed to refresh JWKS").Len())
+	}
+}
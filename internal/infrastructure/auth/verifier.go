@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/golang-jwt/jwt/v5"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// minSecretLength is the shortest HS256 secret accepted, the size of the
+// SHA-256 output. Shorter secrets can be brute forced from a single token.
+const minSecretLength = 32
+
+// Verifier validates bearer JWTs and turns their claims into the caller's
+// identity.
+type Verifier struct {
+	cfg        Config
+	secret     []byte
+	keys       *keySet
+	algorithms []string
+	parser     *jwt.Parser
+}
+
+// NewVerifier accepts the algorithms of cfg that can be checked: HS256 when
+// secret is set and RS256/ES256 when a JWKS source is configured.
+func NewVerifier(cfg Config, secret string) (*Verifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("auth issuer and audience are required")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	v := &Verifier{cfg: cfg, secret: []byte(secret)}
+	if cfg.JWKS.enabled() {
+		v.keys = newKeySet(cfg.JWKS)
+	}
+
+	for _, alg := range cfg.Algorithms {
+		switch alg {
+		case jwt.SigningMethodHS256.Alg():
+			if secret == "" {
+				continue
+			}
+			if len(secret) < minSecretLength {
+				return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes long", minSecretLength)
+			}
+		case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg():
+			if v.keys == nil {
+				continue
+			}
+		default:
+			return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+		}
+		v.algorithms = append(v.algorithms, alg)
+	}
+	if len(v.algorithms) == 0 {
+		return nil, errors.New("no usable signing algorithm: set JWT_SECRET for HS256 or a JWKS source for RS256/ES256")
+	}
+
+	v.parser = jwt.NewParser(
+		jwt.WithValidMethods(v.algorithms),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.leeway()),
+	)
+	return v, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of token and
+// returns the identity it carries.
+func (v *Verifier) Verify(ctx context.Context, token string) (*identity.Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			return v.secret, nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	id := &identity.Identity{
+		Subject: subject,
+		Roles:   rolesClaim(claims[v.cfg.RolesClaim]),
+	}
+	// Customers are identified by their numeric user id
+	if userID, err := strconv.ParseInt(subject, 10, 64); err == nil && userID > 0 {
+		id.UserID = userID
+	}
+
+	return id, nil
+}
+
+// rolesClaim accepts roles as a JSON array or a space separated string.
+func rolesClaim(value any) []string {
+	var roles []string
+	switch v := value.(type) {
+	case []any:
+		for _, r := range v {
+			if s, ok := r.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+	case string:
+		roles = strings.Fields(v)
+	}
+	slices.Sort(roles)
+	return slices.Compact(roles)
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestVerifier(t *testing.T) *Verifier {
+	t.Helper()
+
+	rsaKey, ecKey := testKeys(t)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	doc := jwksDocument(t, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey})
+	if err := os.WriteFile(file, doc, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+
+	v, err := NewVerifier(Config{
+		Issuer:     "https://issuer.example",
+		Audience:   "orders",
+		Algorithms: []string{"HS256", "RS256", "ES256"},
+		JWKS:       JWKSConfig{File: file},
+	}, testSecret)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	return v
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   "https://issuer.example",
+		"aud":   "orders",
+		"sub":   "42",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": []string{"customer"},
+	}
+}
+
+func TestNewVerifierSecretLength(t *testing.T) {
+	cfg := Config{Issuer: "https://issuer.example", Audience: "orders", Algorithms: []string{"HS256"}}
+
+	if _, err := NewVerifier(cfg, testSecret[:minSecretLength-1]); err == nil {
+		t.Error("accepted a secret shorter than 32 bytes")
+	}
+	if _, err := NewVerifier(cfg, testSecret); err != nil {
+		t.Errorf("rejected a 32 byte secret: %v", err)
+	}
+	if _, err := NewVerifier(cfg, ""); err == nil {
+		t.Error("accepted HS256 as the only algorithm without a secret")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, ecKey := testKeys(t)
+	v := newTestVerifier(t)
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    any
+		kid    string
+		claims func(jwt.MapClaims)
+		valid  bool
+	}{
+		{name: "HS256", method: jwt.SigningMethodHS256, key: []byte(testSecret), valid: true},
+		{name: "RS256", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa", valid: true},
+		{name: "ES256", method: jwt.SigningMethodES256, key: ecKey, kid: "ec", valid: true},
+		{name: "wrong secret", method: jwt.SigningMethodHS256, key: []byte(strings.Repeat("x", 32))},
+		{name: "RS256 with the kid of the EC key", method: jwt.SigningMethodRS256, key: rsaKey, kid: "ec"},
+		{name: "ES256 with the kid of the RSA key", method: jwt.SigningMethodES256, key: ecKey, kid: "rsa"},
+		{name: "unknown kid", method: jwt.SigningMethodRS256, key: rsaKey, kid: "retired"},
+		{name: "algorithm not accepted", method: jwt.SigningMethodHS384, key: []byte(testSecret)},
+		{
+			name: "wrong issuer", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa",
+			claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+		},
+		{
+			name: "wrong audience", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa",
+			claims: func(c jwt.MapClaims) { c["aud"] = "billing" },
+		},
+		{
+			name: "expired", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa",
+			claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		},
+		{
+			name: "no expiry", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa",
+			claims: func(c jwt.MapClaims) { delete(c, "exp") },
+		},
+		{
+			name: "no subject", method: jwt.SigningMethodRS256, key: rsaKey, kid: "rsa",
+			claims: func(c jwt.MapClaims) { delete(c, "sub") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			token := jwt.NewWithClaims(tt.method, claims)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+			signed, err := token.SignedString(tt.key)
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			id, err := v.Verify(context.Background(), signed)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("got error %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if id.Subject != "42" || id.UserID != 42 || len(id.Roles) != 1 || id.Roles[0] != "customer" {
+				t.Errorf("got identity %+v", id)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsUnsignedTokens(t *testing.T) {
+	v := newTestVerifier(t)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("got error %v, want ErrInvalidToken", err)
+	}
+}
@@ -4,19 +4,33 @@ import (
 	"fmt"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/database/postgresql"
 	"github.com/joho/godotenv"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // AppConfig holds all application configuration
 type AppConfig struct {
-	AppEnv         string                      `json:"app_env"`
-	ServerAddress  string                      `json:"server_address"`
-	Port           string                      `json:"port"`
-	ContextTimeout time.Duration               `json:"context_timeout"`
-	MongoDB        MongoConfig                 `json:"mongodb"`
-	PgDb           postgresql.PostgresqlConfig `json:"pgdb"`
+	AppEnv            string                      `json:"app_env"`
+	ServerAddress     string                      `json:"server_address"`
+	MetricsAddress    string                      `json:"metrics_address"`
+	TrustedProxies    []string                    `json:"trusted_proxies"`
+	Port              string                      `json:"port"`
+	ContextTimeout    time.Duration               `json:"context_timeout"`
+	ShutdownDelay     time.Duration               `json:"shutdown_delay"`
+	TaxRatesPath      string                      `json:"tax_rates_path"`
+	ShippingRatesPath string                      `json:"shipping_rates_path"`
+	PaymentConfigPath string                      `json:"payment_config_path"`
+	AuthConfigPath    string                      `json:"auth_config_path"`
+	RBACPolicyPath    string                      `json:"rbac_policy_path"`
+	RateLimitPath     string                      `json:"rate_limit_path"`
+	TracingPath       string                      `json:"tracing_path"`
+	JWTSecret         string                      `json:"-"`
+	MongoDB           MongoConfig                 `json:"mongodb"`
+	PgDb              postgresql.PostgresqlConfig `json:"pgdb"`
+	Redis             RedisConfig                 `json:"redis"`
 }
 
 // MongoConfig holds MongoDB specific configuration
@@ -28,6 +42,14 @@ type MongoConfig struct {
 	AuthSource string `json:"auth_source"`
 }
 
+// RedisConfig holds the Redis connection shared by the rate limiter of all
+// instances
+type RedisConfig struct {
+	Addr     string `json:"addr"`
+	Password string `json:"-"`
+	DB       int    `json:"db"`
+}
+
 func LoadConfig() (*AppConfig, error) {
 	if err := godotenv.Load(); err != nil {
 		// Only return error if .env file exists but couldn't be loaded
@@ -41,11 +63,32 @@ func LoadConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("invalid CONTEXT_TIMEOUT value: %w", err)
 	}
 
+	shutdownDelay, err := strconv.Atoi(getEnvOrDefault("SHUTDOWN_DELAY", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHUTDOWN_DELAY value: %w", err)
+	}
+
+	redisDB, err := strconv.Atoi(getEnvOrDefault("REDIS_DB", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_DB value: %w", err)
+	}
+
 	config := &AppConfig{
-		AppEnv:         getEnvOrDefault("APP_ENV", "development"),
-		ServerAddress:  getEnvOrDefault("SERVER_ADDRESS", ":44333"),
-		Port:           getEnvOrDefault("PORT", "44333"),
-		ContextTimeout: time.Duration(timeout) * time.Second,
+		AppEnv:            getEnvOrDefault("APP_ENV", "development"),
+		ServerAddress:     getEnvOrDefault("SERVER_ADDRESS", ":44333"),
+		MetricsAddress:    getEnvOrDefault("METRICS_ADDRESS", ":9090"),
+		TrustedProxies:    splitList(os.Getenv("TRUSTED_PROXIES")),
+		Port:              getEnvOrDefault("PORT", "44333"),
+		ContextTimeout:    time.Duration(timeout) * time.Second,
+		ShutdownDelay:     time.Duration(shutdownDelay) * time.Second,
+		TaxRatesPath:      getEnvOrDefault("TAX_RATES_PATH", "configs/tax_rates.json"),
+		ShippingRatesPath: getEnvOrDefault("SHIPPING_RATES_PATH", "configs/shipping_rates.json"),
+		PaymentConfigPath: getEnvOrDefault("PAYMENT_CONFIG_PATH", "configs/payment_gateway.json"),
+		AuthConfigPath:    getEnvOrDefault("AUTH_CONFIG_PATH", "configs/auth.json"),
+		RBACPolicyPath:    getEnvOrDefault("RBAC_POLICY_PATH", "configs/rbac_policy.json"),
+		RateLimitPath:     getEnvOrDefault("RATE_LIMIT_PATH", "configs/rate_limits.json"),
+		TracingPath:       getEnvOrDefault("TRACING_PATH", "configs/tracing.json"),
+		JWTSecret:         os.Getenv("JWT_SECRET"),
 		MongoDB: MongoConfig{
 			URI:        getEnvOrDefault("MONGO_URI", "mongodb://localhost:27017"),
 			DBName:     getEnvOrDefault("MONGO_DB_NAME", "shopGo"),
@@ -60,6 +103,11 @@ func LoadConfig() (*AppConfig, error) {
 			HostName: getEnvOrDefault("POSTGRES_HOST", "localhost"),
 			Port:     getEnvOrDefault("POSTGRES_PORT", "5432"),
 		},
+		Redis: RedisConfig{
+			Addr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       redisDB,
+		},
 	}
 
 	if err := config.validate(); err != nil {
@@ -79,6 +127,20 @@ func (c *AppConfig) validate() error {
 		return fmt.Errorf("CONTEXT_TIMEOUT must be positive")
 	}
 
+	if c.MetricsAddress == "" || c.MetricsAddress == c.ServerAddress {
+		return fmt.Errorf("METRICS_ADDRESS must be set and differ from SERVER_ADDRESS")
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("TRUSTED_PROXIES: %q is neither an IP address nor a CIDR", proxy)
+		}
+	}
+
+	if c.ShutdownDelay < 0 {
+		return fmt.Errorf("SHUTDOWN_DELAY cannot be negative")
+	}
+
 	if err := c.MongoDB.validate(); err != nil {
 		return fmt.Errorf("mongodb config validation failed: %w", err)
 	}
@@ -108,6 +170,18 @@ func getEnvOrDefault(key, defaultValue string) string {
 }
 
 // GetMongoURI constructs the complete MongoDB URI with credentials if provided
+// splitList splits a comma separated value, dropping empty entries. It
+// returns nil for an empty value.
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
 func (c *MongoConfig) GetMongoURI() string {
 	if c.User != "" && c.Password != "" {
 		return fmt.Sprintf("mongodb://%s:%s@%s/%s?authSource=%s",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/config"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -30,7 +31,8 @@ func NewMongoService(cfg *config.MongoConfig) (*MongoDbContext, error) {
 	opts := options.Client().
 		ApplyURI(cfg.URI).
 		SetAuth(credential).
-		SetServerAPIOptions(serverAPI)
+		SetServerAPIOptions(serverAPI).
+		SetPoolMonitor(metrics.MongoPoolMonitor())
 
 	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
@@ -51,6 +53,11 @@ func NewMongoService(cfg *config.MongoConfig) (*MongoDbContext, error) {
 	}, nil
 }
 
+// Ping checks that the primary of MongoDB answers.
+func (m *MongoDbContext) Ping(ctx context.Context) error {
+	return m.Client.Ping(ctx, readpref.Primary())
+}
+
 func (m *MongoDbContext) Close(ctx context.Context) error {
 	if err := m.Client.Disconnect(ctx); err != nil {
 		logger.Error("Failed to disconnect from MongoDB", zap.Error(err))
@@ -36,6 +36,7 @@ func NewPostgresService(cfg *PostgresqlConfig) (*PostgresContext, error) {
 	config.MinConns = 1
 	config.MaxConnLifetime = 1 * time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
+	config.ConnConfig.Tracer = queryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -56,6 +57,11 @@ func NewPostgresService(cfg *PostgresqlConfig) (*PostgresContext, error) {
 	}, nil
 }
 
+// Ping checks that a connection to PostgreSQL can be used.
+func (p *PostgresContext) Ping(ctx context.Context) error {
+	return p.Pool.Ping(ctx)
+}
+
 func (p *PostgresContext) Close() {
 	if p.Pool != nil {
 		p.Pool.Close()
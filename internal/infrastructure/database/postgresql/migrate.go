@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationLockID is the advisory lock key that serialises migrations when
+// several instances start at the same time.
+const migrationLockID = 7_412_004_001
+
+// Migration is a single schema or data change. Up runs inside a transaction
+// that also records the migration as applied.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx pgx.Tx) error
+}
+
+// LoadSQLMigrations reads files named "<version>_<name>.sql" from fsys.
+func LoadSQLMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		prefix, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name: %s", file)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", file, err)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		sql := string(content)
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			Up: func(ctx context.Context, tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, sql)
+				return err
+			},
+		})
+	}
+
+	return migrations, nil
+}
+
+// Migrate applies the migrations that have not been applied yet in version
+// order.
+func (p *PostgresContext) Migrate(ctx context.Context, migrations []Migration) error {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	conn, err := p.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	_, err = conn.Exec(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations
+        (
+            version    INT PRIMARY KEY,
+            name       TEXT      NOT NULL,
+            applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		logger.Info("Applying migration", zap.Int("version", m.Version), zap.String("name", m.Name))
+
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if err := m.Up(ctx, tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+				m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed to apply migration",
+				zap.Error(err),
+				zap.Int("version", m.Version),
+				zap.String("name", m.Name))
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+)
+
+// queryTracer records a span per query. Only queries made within a trace
+// are recorded, so the polling of background workers leaves no root spans.
+// Arguments are never recorded and literals are removed from the SQL.
+type queryTracer struct{}
+
+type querySpanKey struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	statement := SanitizeSQL(data.SQL)
+	ctx, span := tracing.Tracer().Start(ctx, querySpanName(statement),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(statement),
+		),
+	)
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+var (
+	sqlComment       = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteral = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?\b`)
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+	sqlTable         = regexp.MustCompile(`(?i)\b(?:from|into|update)\s+([a-z_][\w.]*)`)
+)
+
+// SanitizeSQL returns statement without comments and with string and number
+// literals replaced by ?, on a single line. Placeholders such as $1 are kept.
+func SanitizeSQL(statement string) string {
+	statement = sqlComment.ReplaceAllString(statement, " ")
+	statement = sqlStringLiteral.ReplaceAllString(statement, "?")
+	statement = sqlNumberLiteral.ReplaceAllString(statement, "${1}?")
+	return strings.TrimSpace(sqlWhitespace.ReplaceAllString(statement, " "))
+}
+
+// querySpanName names a span by the operation and first table of the
+// statement, e.g. "INSERT order_items".
+func querySpanName(statement string) string {
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+	if match := sqlTable.FindStringSubmatch(statement); match != nil {
+		return operation + " " + match[1]
+	}
+	return operation
+}
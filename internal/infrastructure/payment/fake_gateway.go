@@ -0,0 +1,237 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"os"
+	"sync"
+	"time"
+)
+
+const ProviderFake = "fake"
+
+// Outcome is how the fake gateway answers an authorization.
+type Outcome string
+
+const (
+	OutcomeSucceed Outcome = "succeed"
+	OutcomeDecline Outcome = "decline"
+	OutcomeTimeout Outcome = "timeout"
+)
+
+// GatewayConfig selects and configures the payment gateway.
+type GatewayConfig struct {
+	Provider string     `json:"provider"`
+	Fake     FakeConfig `json:"fake"`
+}
+
+// FakeConfig drives the in-process fake gateway. The outcome of an
+// authorization is looked up by payment method, e.g. "tok_decline", and
+// falls back to DefaultOutcome, so runs are fully deterministic.
+type FakeConfig struct {
+	DefaultOutcome Outcome            `json:"default_outcome"`
+	Outcomes       map[string]Outcome `json:"outcomes"`
+	// LatencyMs delays every call to mimic a remote provider.
+	LatencyMs int `json:"latency_ms"`
+	// TimeoutMs is how long a timing out call hangs when the caller set no
+	// deadline.
+	TimeoutMs int `json:"timeout_ms"`
+}
+
+// LoadGatewayConfig reads GatewayConfig from a JSON file.
+func LoadGatewayConfig(path string) (GatewayConfig, error) {
+	var cfg GatewayConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read payment gateway file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal payment gateway config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// NewGateway returns the PaymentGateway selected by cfg.Provider.
+func NewGateway(cfg GatewayConfig) (service.PaymentGateway, error) {
+	switch cfg.Provider {
+	case ProviderFake, "":
+		return NewFakeGateway(cfg.Fake)
+	default:
+		return nil, fmt.Errorf("unsupported payment provider %q", cfg.Provider)
+	}
+}
+
+type fakeAuthorization struct {
+	amount   float64
+	captured bool
+	voided   bool
+}
+
+type fakeGateway struct {
+	cfg FakeConfig
+
+	mu             sync.Mutex
+	authorizations map[string]*fakeAuthorization
+	captures       map[string]float64
+}
+
+// NewFakeGateway returns a PaymentGateway that never leaves the process. It
+// is meant for local development and end to end tests.
+func NewFakeGateway(cfg FakeConfig) (service.PaymentGateway, error) {
+	if cfg.DefaultOutcome == "" {
+		cfg.DefaultOutcome = OutcomeSucceed
+	}
+	if cfg.TimeoutMs == 0 {
+		cfg.TimeoutMs = 30_000
+	}
+	for method, outcome := range cfg.Outcomes {
+		if !outcome.valid() {
+			return nil, fmt.Errorf("payment method %q: unknown outcome %q", method, outcome)
+		}
+	}
+	if !cfg.DefaultOutcome.valid() {
+		return nil, fmt.Errorf("unknown default outcome %q", cfg.DefaultOutcome)
+	}
+
+	return &fakeGateway{
+		cfg:            cfg,
+		authorizations: make(map[string]*fakeAuthorization),
+		captures:       make(map[string]float64),
+	}, nil
+}
+
+func (o Outcome) valid() bool {
+	return o == OutcomeSucceed || o == OutcomeDecline || o == OutcomeTimeout
+}
+
+func (g *fakeGateway) Name() string {
+	return ProviderFake
+}
+
+func (g *fakeGateway) Authorize(ctx context.Context, req service.AuthorizeRequest) (string, error) {
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+
+	outcome, ok := g.cfg.Outcomes[req.PaymentMethod]
+	if !ok {
+		outcome = g.cfg.DefaultOutcome
+	}
+
+	switch outcome {
+	case OutcomeDecline:
+		return "", fmt.Errorf("%w: card declined by issuer", entity.ErrPaymentDeclined)
+	case OutcomeTimeout:
+		return "", g.hang(ctx)
+	}
+
+	// References derive from the idempotency key so a retry returns the
+	// same authorization
+	authorizationID := "fake_auth_" + req.IdempotencyKey
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.authorizations[authorizationID]; !ok {
+		g.authorizations[authorizationID] = &fakeAuthorization{amount: req.Amount}
+	}
+	return authorizationID, nil
+}
+
+func (g *fakeGateway) Capture(ctx context.Context, authorizationID string, amount float64) (string, error) {
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[authorizationID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown authorization %s", entity.ErrPaymentNotFound, authorizationID)
+	}
+	if auth.voided {
+		return "", fmt.Errorf("%w: authorization %s was voided", entity.ErrPaymentDeclined, authorizationID)
+	}
+	if amount > auth.amount {
+		return "", fmt.Errorf("%w: capture exceeds the authorized amount", entity.ErrPaymentDeclined)
+	}
+
+	captureID := "fake_cap_" + authorizationID[len("fake_auth_"):]
+	if !auth.captured {
+		auth.captured = true
+		g.captures[captureID] = amount
+	}
+	return captureID, nil
+}
+
+func (g *fakeGateway) Void(ctx context.Context, authorizationID string) error {
+	if err := g.wait(ctx); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[authorizationID]
+	if !ok {
+		return fmt.Errorf("%w: unknown authorization %s", entity.ErrPaymentNotFound, authorizationID)
+	}
+	if auth.captured {
+		return fmt.Errorf("%w: authorization %s is already captured", entity.ErrPaymentDeclined, authorizationID)
+	}
+	auth.voided = true
+	return nil
+}
+
+func (g *fakeGateway) Refund(ctx context.Context, captureID string, amount float64) (string, error) {
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	captured, ok := g.captures[captureID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown capture %s", entity.ErrPaymentNotFound, captureID)
+	}
+	if amount > captured {
+		return "", fmt.Errorf("%w: refund exceeds the captured amount", entity.ErrPaymentDeclined)
+	}
+	g.captures[captureID] = entity.RoundMoney(captured - amount)
+
+	return fmt.Sprintf("fake_ref_%s_%.2f", captureID[len("fake_cap_"):], amount), nil
+}
+
+// wait simulates network latency.
+func (g *fakeGateway) wait(ctx context.Context) error {
+	if g.cfg.LatencyMs <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(g.cfg.LatencyMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", entity.ErrPaymentTimeout, ctx.Err())
+	}
+}
+
+// hang simulates a provider that never answers.
+func (g *fakeGateway) hang(ctx context.Context) error {
+	timer := time.NewTimer(time.Duration(g.cfg.TimeoutMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return entity.ErrPaymentTimeout
+}
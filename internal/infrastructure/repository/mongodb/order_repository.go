@@ -24,7 +24,7 @@ package mongodb
 //		logger.Error("Failed to create order in MongoDB",
 //			zap.Error(err),
 //			zap.String("user_id", order.UserID.Hex()),
-//			zap.Any("order", order),
+//			zap.Object("order", entity.RedactedOrder(*order)),
 //		)
 //		return err
 //	}
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	pgdb "github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/database/postgresql"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// AddressBackfillMigration parses the legacy free text shipping_addr of
+// existing orders into structured shipping and billing addresses. Parsing is
+// best-effort: text that cannot be recognised is kept in the address lines.
+func AddressBackfillMigration() pgdb.Migration {
+	return pgdb.Migration{
+		Version: 3,
+		Name:    "backfill_structured_addresses",
+		Up:      backfillAddresses,
+	}
+}
+
+func backfillAddresses(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `
+        SELECT id, shipping_addr
+        FROM orders
+        WHERE shipping_address IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	addresses := make(map[int64]entity.Address)
+	for rows.Next() {
+		var id int64
+		var raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		addresses[id] = entity.ParseAddress(raw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	incomplete := 0
+	for id, addr := range addresses {
+		if addr.Validate() != nil {
+			incomplete++
+		}
+
+		_, err := tx.Exec(ctx, `
+            UPDATE orders
+            SET shipping_address = $2, billing_address = $2
+            WHERE id = $1`,
+			id, addr)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to backfill order address", zap.Error(err), zap.Int64("orderId", id))
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).Info("Backfilled structured addresses",
+		zap.Int("orders", len(addresses)),
+		zap.Int("incomplete", incomplete),
+	)
+	return nil
+}
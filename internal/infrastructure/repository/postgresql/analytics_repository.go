@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// analyticsRepository runs the sales report aggregates. Order timestamps are
+// stored as TIMESTAMPTZ and converted to the report's timezone for
+// bucketing.
+type analyticsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAnalyticsRepository(db *pgxpool.Pool) *analyticsRepository {
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+func (r *analyticsRepository) Revenue(
+	ctx context.Context,
+	rng entity.ReportRange,
+	interval entity.ReportInterval,
+) ([]entity.RevenueBucket, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT to_char(date_trunc($1, created_at AT TIME ZONE $2), 'YYYY-MM-DD') AS period,
+               count(*), coalesce(sum(total_amount), 0)::float8
+        FROM orders
+        WHERE created_at >= $3 AND created_at < $4 AND status <> $5
+        GROUP BY period
+        ORDER BY period`,
+		string(interval), rng.Location.String(), rng.From.UTC(), rng.To.UTC(), entity.OrderStatusCancelled)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to aggregate revenue", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []entity.RevenueBucket{}
+	for rows.Next() {
+		var b entity.RevenueBucket
+		if err := rows.Scan(&b.PeriodStart, &b.OrderCount, &b.Revenue); err != nil {
+			logger.FromContext(ctx).Error("failed to scan revenue bucket", zap.Error(err))
+			return nil, err
+		}
+		b.Revenue = entity.RoundMoney(b.Revenue)
+		if b.OrderCount > 0 {
+			b.AverageOrderValue = entity.RoundMoney(b.Revenue / float64(b.OrderCount))
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+func (r *analyticsRepository) TopProducts(
+	ctx context.Context,
+	rng entity.ReportRange,
+	by entity.TopProductsOrder,
+	limit int,
+) ([]entity.TopProduct, error) {
+	orderBy := "quantity DESC, revenue DESC"
+	if by == entity.TopProductsByRevenue {
+		orderBy = "revenue DESC, quantity DESC"
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT oi.product_id, coalesce(p.name, ''), coalesce(p.category, ''),
+               sum(oi.quantity) AS quantity, sum(oi.total_price)::float8 AS revenue,
+               count(DISTINCT oi.order_id)
+        FROM order_items oi
+        JOIN orders o ON o.id = oi.order_id
+        LEFT JOIN products p ON p.id = oi.product_id
+        WHERE o.created_at >= $1 AND o.created_at < $2 AND o.status <> $3
+        GROUP BY oi.product_id, p.name, p.category
+        ORDER BY `+orderBy+`, oi.product_id
+        LIMIT $4`,
+		rng.From.UTC(), rng.To.UTC(), entity.OrderStatusCancelled, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to aggregate top products", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []entity.TopProduct{}
+	for rows.Next() {
+		var p entity.TopProduct
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.Category, &p.Quantity, &p.Revenue, &p.OrderCount); err != nil {
+			logger.FromContext(ctx).Error("failed to scan top product", zap.Error(err))
+			return nil, err
+		}
+		p.Revenue = entity.RoundMoney(p.Revenue)
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+// StatusFunnel reads the stages each order reached from its status change
+// events, so later cancellations or returns do not erase earlier progress.
+func (r *analyticsRepository) StatusFunnel(ctx context.Context, rng entity.ReportRange) (*entity.StatusFunnel, error) {
+	funnel := &entity.StatusFunnel{ByStatus: map[entity.OrderStatus]int64{}}
+
+	err := r.db.QueryRow(ctx, `
+        WITH placed AS (
+            SELECT id, status
+            FROM orders
+            WHERE created_at >= $1 AND created_at < $2
+        ), reached AS (
+            SELECT p.id, p.status, array_agg(DISTINCT e.data->>'to') FILTER (WHERE e.id IS NOT NULL) AS statuses
+            FROM placed p
+            LEFT JOIN order_events e ON e.aggregate_id = p.id AND e.type = $3
+            GROUP BY p.id, p.status
+        )
+        SELECT count(*),
+               count(*) FILTER (WHERE status IN ('paid', 'partially_shipped', 'shipped', 'delivered')
+                   OR statuses && ARRAY['paid', 'partially_shipped', 'shipped', 'delivered']),
+               count(*) FILTER (WHERE status IN ('partially_shipped', 'shipped', 'delivered')
+                   OR statuses && ARRAY['partially_shipped', 'shipped', 'delivered']),
+               count(*) FILTER (WHERE status = 'delivered' OR statuses && ARRAY['delivered']),
+               count(*) FILTER (WHERE status = 'cancelled')
+        FROM reached`,
+		rng.From.UTC(), rng.To.UTC(), entity.OrderEventStatusChanged,
+	).Scan(&funnel.Placed, &funnel.Paid, &funnel.Shipped, &funnel.Delivered, &funnel.Cancelled)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to aggregate status funnel", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT status, count(*)
+        FROM orders
+        WHERE created_at >= $1 AND created_at < $2
+        GROUP BY status`, rng.From.UTC(), rng.To.UTC())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count orders by status", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status entity.OrderStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			logger.FromContext(ctx).Error("failed to scan status count", zap.Error(err))
+			return nil, err
+		}
+		funnel.ByStatus[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	funnel.ComputeRates()
+	return funnel, nil
+}
@@ -0,0 +1,190 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, rotated_to_id,
+               created_at, updated_at`
+
+type apiKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey) error {
+	err := insertAPIKey(ctx, r.db, key)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to insert api key", zap.Error(err), zap.String("name", key.Name))
+	}
+	return err
+}
+
+func (r *apiKeyRepository) GetAPIKey(ctx context.Context, keyID int64) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, keyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", entity.ErrAPIKeyNotFound, keyID)
+		}
+		logger.FromContext(ctx).Error("failed to get api key", zap.Error(err), zap.Int64("apiKeyId", keyID))
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrAPIKeyNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get api key by prefix", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list api keys", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan api key", zap.Error(err))
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) RotateAPIKey(
+	ctx context.Context,
+	keyID int64,
+	replacement *entity.APIKey,
+	retireAt time.Time,
+) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	old, err := scanAPIKey(tx.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 FOR UPDATE`, keyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %d", entity.ErrAPIKeyNotFound, keyID)
+		}
+		logger.FromContext(ctx).Error("failed to lock api key", zap.Error(err), zap.Int64("apiKeyId", keyID))
+		return err
+	}
+	if old.RevokedAt != nil {
+		return fmt.Errorf("%w: %d", entity.ErrAPIKeyRevoked, keyID)
+	}
+
+	if err := insertAPIKey(ctx, tx, replacement); err != nil {
+		logger.FromContext(ctx).Error("failed to insert rotated api key", zap.Error(err), zap.Int64("apiKeyId", keyID))
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE api_keys
+        SET revoked_at = $2, rotated_to_id = $3, updated_at = $4
+        WHERE id = $1`,
+		keyID, retireAt, replacement.ID, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to retire api key", zap.Error(err), zap.Int64("apiKeyId", keyID))
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, keyID int64, at time.Time) error {
+	// Revoking a rotated key cuts its grace period short
+	tag, err := r.db.Exec(ctx, `
+        UPDATE api_keys
+        SET revoked_at = $2, updated_at = $2
+        WHERE id = $1 AND (revoked_at IS NULL OR revoked_at > $2)`,
+		keyID, at)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to revoke api key", zap.Error(err), zap.Int64("apiKeyId", keyID))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetAPIKey(ctx, keyID); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %d", entity.ErrAPIKeyRevoked, keyID)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, keyID int64, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE api_keys
+        SET last_used_at = $2
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - interval '1 minute')`,
+		keyID, at)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record api key use", zap.Error(err), zap.Int64("apiKeyId", keyID))
+	}
+	return err
+}
+
+func insertAPIKey(ctx context.Context, q querier, key *entity.APIKey) error {
+	now := time.Now()
+	return q.QueryRow(ctx, `
+        INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        RETURNING id, created_at, updated_at`,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+		now,
+	).Scan(&key.ID, &key.CreatedAt, &key.UpdatedAt)
+}
+
+func scanAPIKey(row pgx.Row) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.RotatedToID,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,258 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+const couponColumns = `id, code, description, type, value, min_order_value, valid_from, valid_until,
+               max_uses, max_uses_per_user, times_used, active, created_at, updated_at`
+
+type couponRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCouponRepository(db *pgxpool.Pool) *couponRepository {
+	return &couponRepository{
+		db: db,
+	}
+}
+
+func (r *couponRepository) CreateCoupon(ctx context.Context, coupon *entity.Coupon) error {
+	query := `
+        INSERT INTO coupons (code, description, type, value, min_order_value, valid_from, valid_until,
+                             max_uses, max_uses_per_user, active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+        RETURNING id, times_used, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		coupon.Code,
+		coupon.Description,
+		coupon.Type,
+		coupon.Value,
+		coupon.MinOrderValue,
+		coupon.ValidFrom,
+		coupon.ValidUntil,
+		coupon.MaxUses,
+		coupon.MaxUsesPerUser,
+		coupon.Active,
+		time.Now(),
+	).Scan(&coupon.ID, &coupon.TimesUsed, &coupon.CreatedAt, &coupon.UpdatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrCouponCodeTaken
+		}
+		logger.FromContext(ctx).Error("failed to insert coupon", zap.Error(err), zap.String("code", coupon.Code))
+		return err
+	}
+
+	return nil
+}
+
+func (r *couponRepository) UpdateCoupon(ctx context.Context, coupon *entity.Coupon) error {
+	query := `
+        UPDATE coupons
+        SET code = $2, description = $3, type = $4, value = $5, min_order_value = $6, valid_from = $7,
+            valid_until = $8, max_uses = $9, max_uses_per_user = $10, active = $11, updated_at = $12
+        WHERE id = $1
+        RETURNING times_used, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		coupon.ID,
+		coupon.Code,
+		coupon.Description,
+		coupon.Type,
+		coupon.Value,
+		coupon.MinOrderValue,
+		coupon.ValidFrom,
+		coupon.ValidUntil,
+		coupon.MaxUses,
+		coupon.MaxUsesPerUser,
+		coupon.Active,
+		time.Now(),
+	).Scan(&coupon.TimesUsed, &coupon.CreatedAt, &coupon.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ErrCouponNotFound
+		}
+		if isUniqueViolation(err) {
+			return entity.ErrCouponCodeTaken
+		}
+		logger.FromContext(ctx).Error("failed to update coupon", zap.Error(err), zap.Int64("couponId", coupon.ID))
+		return err
+	}
+
+	return nil
+}
+
+func (r *couponRepository) GetCouponByID(ctx context.Context, couponID int64) (*entity.Coupon, error) {
+	query := `
+        SELECT ` + couponColumns + `
+        FROM coupons
+        WHERE id = $1`
+
+	coupon, err := scanCoupon(r.db.QueryRow(ctx, query, couponID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrCouponNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get coupon", zap.Error(err), zap.Int64("couponId", couponID))
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+func (r *couponRepository) GetCouponByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	query := `
+        SELECT ` + couponColumns + `
+        FROM coupons
+        WHERE code = $1`
+
+	coupon, err := scanCoupon(r.db.QueryRow(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrCouponNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get coupon by code", zap.Error(err), zap.String("code", code))
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+func (r *couponRepository) ListCoupons(ctx context.Context, limit, offset int) ([]entity.Coupon, int64, error) {
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT count(*) FROM coupons`).Scan(&total); err != nil {
+		logger.FromContext(ctx).Error("failed to count coupons", zap.Error(err))
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT ` + couponColumns + `
+        FROM coupons
+        ORDER BY id DESC
+        LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list coupons", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	coupons := make([]entity.Coupon, 0, limit)
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan coupon", zap.Error(err))
+			return nil, 0, err
+		}
+		coupons = append(coupons, *coupon)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate coupons", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return coupons, total, nil
+}
+
+func (r *couponRepository) CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error) {
+	var count int
+	query := `
+        SELECT count(*)
+        FROM coupon_redemptions
+        WHERE coupon_id = $1 AND user_id = $2`
+
+	if err := r.db.QueryRow(ctx, query, couponID, userID).Scan(&count); err != nil {
+		logger.FromContext(ctx).Error("failed to count coupon redemptions",
+			zap.Error(err),
+			zap.Int64("couponId", couponID),
+			zap.Int64("userId", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func scanCoupon(row pgx.Row) (*entity.Coupon, error) {
+	coupon := &entity.Coupon{}
+	err := row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Description,
+		&coupon.Type,
+		&coupon.Value,
+		&coupon.MinOrderValue,
+		&coupon.ValidFrom,
+		&coupon.ValidUntil,
+		&coupon.MaxUses,
+		&coupon.MaxUsesPerUser,
+		&coupon.TimesUsed,
+		&coupon.Active,
+		&coupon.CreatedAt,
+		&coupon.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// redeemCoupon atomically counts one use of a coupon by a user for an order.
+// The UPDATE locks the coupon row, so concurrent redemptions of the same
+// coupon are serialised and the per-user count cannot race.
+func redeemCoupon(ctx context.Context, tx pgx.Tx, couponID, userID, orderID int64) error {
+	var maxUsesPerUser *int
+	err := tx.QueryRow(ctx, `
+        UPDATE coupons
+        SET times_used = times_used + 1
+        WHERE id = $1 AND active AND (max_uses IS NULL OR times_used < max_uses)
+        RETURNING max_uses_per_user`,
+		couponID,
+	).Scan(&maxUsesPerUser)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ErrCouponUsageLimitReached
+		}
+		return err
+	}
+
+	if maxUsesPerUser != nil {
+		var used int
+		err := tx.QueryRow(ctx, `
+            SELECT count(*)
+            FROM coupon_redemptions
+            WHERE coupon_id = $1 AND user_id = $2`,
+			couponID, userID,
+		).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used >= *maxUsesPerUser {
+			return entity.ErrCouponUsageLimitReached
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO coupon_redemptions (coupon_id, user_id, order_id)
+        VALUES ($1, $2, $3)`,
+		couponID, userID, orderID)
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+type inventoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInventoryRepository(db *pgxpool.Pool) *inventoryRepository {
+	return &inventoryRepository{
+		db: db,
+	}
+}
+
+func (r *inventoryRepository) ReserveStock(ctx context.Context, orderID int64) error {
+	return r.changeReservation(ctx, orderID, "released", "reserved", -1)
+}
+
+func (r *inventoryRepository) ReleaseStock(ctx context.Context, orderID int64) error {
+	return r.changeReservation(ctx, orderID, "reserved", "released", 1)
+}
+
+// changeReservation moves the reservation of an order from one state to the
+// other and adjusts stock by sign times each item quantity. A missing
+// reservation counts as released.
+func (r *inventoryRepository) changeReservation(ctx context.Context, orderID int64, from, to string, sign int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM stock_reservations WHERE order_id = $1`, orderID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		status = "released"
+	} else if err != nil {
+		logger.FromContext(ctx).Error("failed to get stock reservation", zap.Error(err), zap.Int64("orderId", orderID))
+		return err
+	}
+	if status != from {
+		return nil
+	}
+
+	// Adjust in product order so concurrent reservations cannot deadlock
+	items := append([]entity.OrderItem(nil), order.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+	for _, item := range items {
+		if err := adjustStock(ctx, tx, item.ProductID, sign*item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO stock_reservations (order_id, status, updated_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (order_id) DO UPDATE SET status = EXCLUDED.status, updated_at = EXCLUDED.updated_at`,
+		orderID, to, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to save stock reservation", zap.Error(err), zap.Int64("orderId", orderID))
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,68 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	pgdb "github.com/Sinet2000/Martix-Orders-Go/internal/infrastructure/database/postgresql"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// OrderEventBackfillMigration records an order_created event with the
+// current state of every order placed before event sourcing, so the event
+// log is complete and the projections can be rebuilt from it.
+func OrderEventBackfillMigration() pgdb.Migration {
+	return pgdb.Migration{
+		Version: 13,
+		Name:    "backfill_order_events",
+		Up:      backfillOrderEvents,
+	}
+}
+
+func backfillOrderEvents(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `
+        SELECT id
+        FROM orders o
+        WHERE NOT EXISTS (SELECT 1 FROM order_events e WHERE e.aggregate_id = o.id)
+        ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return err
+	}
+
+	if err := takeTransactionID(ctx, tx); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		order, err := getOrder(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		event, err := entity.NewOrderCreatedEvent(order)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+            INSERT INTO order_events (aggregate_id, sequence, type, data, occurred_at)
+            VALUES ($1, 1, $2, $3, $4)`,
+			id, event.Type, event.Data, event.OccurredAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to backfill order event", zap.Error(err), zap.Int64("orderId", id))
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE orders SET event_sequence = 1 WHERE id = $1`, id); err != nil {
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).Info("Backfilled order events", zap.Int("orders", len(ids)))
+	return nil
+}
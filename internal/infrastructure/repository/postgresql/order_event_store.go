@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+// SnapshotInterval is the number of events after which the state of an
+// order is snapshotted, so rehydration never replays more than that.
+const SnapshotInterval = 20
+
+// appendOrderEvents writes events after expectedVersion and projects them
+// into the orders tables within tx, so readers and foreign keys see the
+// change as soon as it commits.
+func appendOrderEvents(ctx context.Context, tx pgx.Tx, orderID int64, expectedVersion int64, events []entity.OrderEvent) error {
+	if err := takeTransactionID(ctx, tx); err != nil {
+		return err
+	}
+
+	for i := range events {
+		events[i].OrderID = orderID
+		events[i].Sequence = expectedVersion + int64(i) + 1
+
+		err := tx.QueryRow(ctx, `
+            INSERT INTO order_events (aggregate_id, sequence, type, data, occurred_at)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING id`,
+			orderID,
+			events[i].Sequence,
+			events[i].Type,
+			events[i].Data,
+			events[i].OccurredAt,
+		).Scan(&events[i].ID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return entity.ErrConcurrencyConflict
+			}
+			logger.FromContext(ctx).Error("failed to append order event",
+				zap.Error(err),
+				zap.Int64("orderId", orderID),
+				zap.String("type", string(events[i].Type)))
+			return err
+		}
+
+		if err := projectOrderEvent(ctx, tx, events[i]); err != nil {
+			return err
+		}
+	}
+
+	version := expectedVersion + int64(len(events))
+	if version/SnapshotInterval > expectedVersion/SnapshotInterval {
+		return saveOrderSnapshot(ctx, tx, orderID)
+	}
+	return nil
+}
+
+// takeTransactionID assigns tx its transaction id, if it has none yet, before
+// any event id is taken. The projection workers rely on this to tell when a
+// missing event id can no longer be committed (see ProjectionWorker).
+func takeTransactionID(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SELECT pg_current_xact_id()`)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to take transaction id", zap.Error(err))
+	}
+	return err
+}
+
+func loadOrderAggregate(ctx context.Context, q querier, orderID int64) (*entity.OrderAggregate, error) {
+	var snapshot *entity.OrderAggregate
+	var state entity.OrderAggregate
+	err := q.QueryRow(ctx, `
+        SELECT state
+        FROM order_snapshots
+        WHERE aggregate_id = $1`, orderID).Scan(&state)
+	switch {
+	case err == nil:
+		snapshot = &state
+	case !errors.Is(err, pgx.ErrNoRows):
+		logger.FromContext(ctx).Error("failed to get order snapshot", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+
+	var after int64
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	events, err := readOrderEvents(ctx, q, `WHERE aggregate_id = $1 AND sequence > $2 ORDER BY sequence`, orderID, after)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate, err := entity.RehydrateOrder(snapshot, events)
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
+	}
+	return aggregate, err
+}
+
+func saveOrderSnapshot(ctx context.Context, tx pgx.Tx, orderID int64) error {
+	aggregate, err := loadOrderAggregate(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO order_snapshots (aggregate_id, sequence, state, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (aggregate_id) DO UPDATE
+            SET sequence = EXCLUDED.sequence, state = EXCLUDED.state, created_at = EXCLUDED.created_at`,
+		orderID, aggregate.Version, aggregate, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to save order snapshot", zap.Error(err), zap.Int64("orderId", orderID))
+	}
+	return err
+}
+
+func readOrderEvents(ctx context.Context, q querier, where string, args ...any) ([]entity.OrderEvent, error) {
+	rows, err := q.Query(ctx, `
+        SELECT id, aggregate_id, sequence, type, data, occurred_at
+        FROM order_events
+        `+where, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to read order events", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entity.OrderEvent{}
+	for rows.Next() {
+		var e entity.OrderEvent
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Sequence, &e.Type, &e.Data, &e.OccurredAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan order event", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
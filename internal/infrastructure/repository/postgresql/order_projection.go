@@ -0,0 +1,175 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// orderProjection maintains orders, order_items and order_discounts as read
+// models of the order events.
+type orderProjection struct{}
+
+func NewOrderProjection() Projection {
+	return orderProjection{}
+}
+
+func (orderProjection) Name() string {
+	return "orders"
+}
+
+func (orderProjection) Apply(ctx context.Context, tx pgx.Tx, event entity.OrderEvent) error {
+	return projectOrderEvent(ctx, tx, event)
+}
+
+// Reset marks every order as unprojected so a replay rewrites it. Rows are
+// overwritten rather than deleted because shipments, payments and returns
+// reference them.
+func (orderProjection) Reset(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `UPDATE orders SET event_sequence = 0`)
+	return err
+}
+
+// projectOrderEvent applies an event to the orders tables. Events the row
+// already reflects are skipped, which makes projecting idempotent.
+func projectOrderEvent(ctx context.Context, tx pgx.Tx, event entity.OrderEvent) error {
+	var projected int64
+	err := tx.QueryRow(ctx, `
+        SELECT event_sequence
+        FROM orders
+        WHERE id = $1
+        FOR UPDATE`, event.OrderID).Scan(&projected)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.FromContext(ctx).Error("failed to get projected order version", zap.Error(err), zap.Int64("orderId", event.OrderID))
+		return err
+	}
+	if event.Sequence <= projected {
+		return nil
+	}
+
+	switch event.Type {
+	case entity.OrderEventCreated:
+		var order entity.Order
+		if err := json.Unmarshal(event.Data, &order); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+		return projectOrderCreated(ctx, tx, &order, event.Sequence)
+	case entity.OrderEventStatusChanged:
+		var change entity.OrderStatusChanged
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+		tag, err := tx.Exec(ctx, `
+            UPDATE orders
+            SET status = $2, updated_at = $3, event_sequence = $4
+            WHERE id = $1`,
+			event.OrderID, change.To, event.OccurredAt, event.Sequence)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order status", zap.Error(err), zap.Int64("orderId", event.OrderID))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: order %d changed before it was created", entity.ErrInvalidOrderEvent, event.OrderID)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type %q", entity.ErrInvalidOrderEvent, event.Type)
+	}
+}
+
+func projectOrderCreated(ctx context.Context, tx pgx.Tx, order *entity.Order, sequence int64) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO orders (id, user_id, subtotal, discount_amount, tax_amount, prices_include_tax, shipping_method,
+                            shipping_cost, total_amount, status, shipping_addr, shipping_address,
+                            billing_address, notes, created_at, updated_at, event_sequence)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+        ON CONFLICT (id) DO UPDATE
+            SET user_id = EXCLUDED.user_id, subtotal = EXCLUDED.subtotal,
+                discount_amount = EXCLUDED.discount_amount, tax_amount = EXCLUDED.tax_amount,
+                prices_include_tax = EXCLUDED.prices_include_tax, shipping_method = EXCLUDED.shipping_method,
+                shipping_cost = EXCLUDED.shipping_cost, total_amount = EXCLUDED.total_amount,
+                status = EXCLUDED.status, shipping_addr = EXCLUDED.shipping_addr,
+                shipping_address = EXCLUDED.shipping_address, billing_address = EXCLUDED.billing_address,
+                notes = EXCLUDED.notes, created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at,
+                event_sequence = EXCLUDED.event_sequence`,
+		order.ID,
+		order.UserID,
+		order.Subtotal,
+		order.DiscountAmount,
+		order.TaxAmount,
+		order.PricesIncludeTax,
+		order.ShippingMethod,
+		order.ShippingCost,
+		order.TotalAmount,
+		order.Status,
+		order.ShippingAddress.String(),
+		order.ShippingAddress,
+		order.BillingAddress,
+		order.Notes,
+		order.CreatedAt,
+		order.UpdatedAt,
+		sequence,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to project order", zap.Error(err), zap.Int64("orderId", order.ID))
+		return err
+	}
+
+	for _, item := range order.Items {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price, tax_rate, tax_amount)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            ON CONFLICT (id) DO UPDATE
+                SET order_id = EXCLUDED.order_id, product_id = EXCLUDED.product_id,
+                    quantity = EXCLUDED.quantity, unit_price = EXCLUDED.unit_price,
+                    total_price = EXCLUDED.total_price, tax_rate = EXCLUDED.tax_rate,
+                    tax_amount = EXCLUDED.tax_amount`,
+			item.ID,
+			order.ID,
+			item.ProductID,
+			item.Quantity,
+			item.UnitPrice,
+			item.TotalPrice,
+			item.TaxRate,
+			item.TaxAmount,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order item",
+				zap.Error(err),
+				zap.Int64("orderId", order.ID),
+				zap.Int64("orderItemId", item.ID))
+			return err
+		}
+	}
+
+	for _, discount := range order.Discounts {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO order_discounts (id, order_id, coupon_id, code, type, description, amount)
+            VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7)
+            ON CONFLICT (id) DO UPDATE
+                SET order_id = EXCLUDED.order_id, coupon_id = EXCLUDED.coupon_id, code = EXCLUDED.code,
+                    type = EXCLUDED.type, description = EXCLUDED.description, amount = EXCLUDED.amount`,
+			discount.ID,
+			order.ID,
+			discount.CouponID,
+			discount.Code,
+			discount.Type,
+			discount.Description,
+			discount.Amount,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order discount",
+				zap.Error(err),
+				zap.Int64("orderId", order.ID),
+				zap.Int64("discountId", discount.ID))
+			return err
+		}
+	}
+
+	return nil
+}
@@ -9,9 +9,14 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-	"time"
 )
 
+// querier is satisfied by both the pool and transactions.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type orderRepository struct {
 	db *pgxpool.Pool
 	// logger *zap.Logger
@@ -23,54 +28,50 @@ func NewOrderRepository(db *pgxpool.Pool) *orderRepository {
 	}
 }
 
+// CreateOrder records the order as an order_created event. IDs are taken
+// from the table sequences up front so the event carries them and replaying
+// it always produces the same rows.
 func (r *orderRepository) CreateOrder(ctx context.Context, order *entity.Order) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		logger.Error("failed to begin transaction", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	// Insert order
-	query := `
-        INSERT INTO orders (user_id, total_amount, status, shipping_addr, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id`
-
-	err = tx.QueryRow(ctx, query,
-		order.UserID,
-		order.TotalAmount,
-		order.Status,
-		order.ShippingAddr,
-		time.Now(),
-		time.Now(),
-	).Scan(&order.ID)
+	if order.ID, err = nextID(ctx, tx, "orders"); err != nil {
+		return err
+	}
+	for i := range order.Items {
+		order.Items[i].OrderID = order.ID
+		if order.Items[i].ID, err = nextID(ctx, tx, "order_items"); err != nil {
+			return err
+		}
+	}
+	for i := range order.Discounts {
+		order.Discounts[i].OrderID = order.ID
+		if order.Discounts[i].ID, err = nextID(ctx, tx, "order_discounts"); err != nil {
+			return err
+		}
+	}
 
+	event, err := entity.NewOrderCreatedEvent(order)
 	if err != nil {
-		logger.Error("failed to insert order", zap.Error(err))
+		return err
+	}
+	if err := appendOrderEvents(ctx, tx, order.ID, 0, []entity.OrderEvent{event}); err != nil {
 		return err
 	}
 
-	// Insert order items
-	for i := range order.Items {
-		query := `
-            INSERT INTO order_items (order_id, product_id, quantity, unit_price, total_price)
-            VALUES ($1, $2, $3, $4, $5)
-            RETURNING id`
-
-		err = tx.QueryRow(ctx, query,
-			order.ID,
-			order.Items[i].ProductID,
-			order.Items[i].Quantity,
-			order.Items[i].UnitPrice,
-			order.Items[i].TotalPrice,
-		).Scan(&order.Items[i].ID)
-
-		if err != nil {
-			logger.Error("failed to insert order item",
-				zap.Error(err),
-				zap.Int64("orderId", order.ID),
-				zap.Int64("productId", order.Items[i].ProductID))
+	// Count coupon usage in the same transaction
+	for _, discount := range order.Discounts {
+		if err := redeemCoupon(ctx, tx, discount.CouponID, order.UserID, order.ID); err != nil {
+			if !errors.Is(err, entity.ErrCouponUsageLimitReached) {
+				logger.FromContext(ctx).Error("failed to redeem coupon",
+					zap.Error(err),
+					zap.Int64("orderId", order.ID),
+					zap.Int64("couponId", discount.CouponID))
+			}
 			return err
 		}
 	}
@@ -78,42 +79,66 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *entity.Order)
 	return tx.Commit(ctx)
 }
 
+// nextID reserves the next value of the id sequence of table.
+func nextID(ctx context.Context, tx pgx.Tx, table string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence($1, 'id'))`, table).Scan(&id)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to allocate id", zap.Error(err), zap.String("table", table))
+	}
+	return id, err
+}
+
 func (r *orderRepository) GetOrderByID(ctx context.Context, orderID int64) (*entity.Order, error) {
+	return getOrder(ctx, r.db, orderID)
+}
+
+func getOrder(ctx context.Context, q querier, orderID int64) (*entity.Order, error) {
 	order := &entity.Order{}
 
 	// Get order details
 	query := `
-        SELECT id, user_id, total_amount, status, shipping_addr, created_at, updated_at
+        SELECT id, user_id, subtotal, discount_amount, tax_amount, prices_include_tax, shipping_method,
+               shipping_cost, total_amount, status, shipping_address, billing_address, notes, created_at,
+               updated_at
         FROM orders
         WHERE id = $1`
 
-	err := r.db.QueryRow(ctx, query, orderID).Scan(
+	err := q.QueryRow(ctx, query, orderID).Scan(
 		&order.ID,
 		&order.UserID,
+		&order.Subtotal,
+		&order.DiscountAmount,
+		&order.TaxAmount,
+		&order.PricesIncludeTax,
+		&order.ShippingMethod,
+		&order.ShippingCost,
 		&order.TotalAmount,
 		&order.Status,
-		&order.ShippingAddr,
+		&order.ShippingAddress,
+		&order.BillingAddress,
+		&order.Notes,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("order not found: %d", orderID)
+			return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
 		}
-		logger.Error("failed to get order", zap.Error(err), zap.Int64("orderId", orderID))
+		logger.FromContext(ctx).Error("failed to get order", zap.Error(err), zap.Int64("orderId", orderID))
 		return nil, err
 	}
 
 	// Get order items
 	query = `
-        SELECT id, product_id, quantity, unit_price, total_price
+        SELECT id, product_id, quantity, unit_price, total_price, tax_rate, tax_amount
         FROM order_items
         WHERE order_id = $1`
 
-	rows, err := r.db.Query(ctx, query, orderID)
+	rows, err := q.Query(ctx, query, orderID)
 	if err != nil {
-		logger.Error("failed to get order items", zap.Error(err), zap.Int64("orderId", orderID))
+		logger.FromContext(ctx).Error("failed to get order items", zap.Error(err), zap.Int64("orderId", orderID))
 		return nil, err
 	}
 	defer rows.Close()
@@ -126,14 +151,192 @@ func (r *orderRepository) GetOrderByID(ctx context.Context, orderID int64) (*ent
 			&item.Quantity,
 			&item.UnitPrice,
 			&item.TotalPrice,
+			&item.TaxRate,
+			&item.TaxAmount,
 		)
 		if err != nil {
-			logger.Error("failed to scan order item", zap.Error(err))
+			logger.FromContext(ctx).Error("failed to scan order item", zap.Error(err))
 			return nil, err
 		}
 		item.OrderID = orderID
 		order.Items = append(order.Items, item)
 	}
 
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate order items", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+
+	// Get discount lines
+	query = `
+        SELECT id, coupon_id, code, type, description, amount
+        FROM order_discounts
+        WHERE order_id = $1`
+
+	discountRows, err := q.Query(ctx, query, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get order discounts", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	defer discountRows.Close()
+
+	order.Discounts = []entity.OrderDiscount{}
+	for discountRows.Next() {
+		var discount entity.OrderDiscount
+		var couponID *int64
+		err := discountRows.Scan(
+			&discount.ID,
+			&couponID,
+			&discount.Code,
+			&discount.Type,
+			&discount.Description,
+			&discount.Amount,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan order discount", zap.Error(err))
+			return nil, err
+		}
+		if couponID != nil {
+			discount.CouponID = *couponID
+		}
+		discount.OrderID = orderID
+		order.Discounts = append(order.Discounts, discount)
+	}
+
+	if err := discountRows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate order discounts", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	order.TaxSummary = entity.BuildTaxSummary(order.Items, entity.ItemDiscount(order.Discounts), order.Subtotal)
+
 	return order, nil
 }
+
+// SearchOrders runs a full-text search over the shipping address, product
+// names and notes of orders, ordered by relevance. It returns the requested
+// page of results and the total number of matching orders.
+func (r *orderRepository) SearchOrders(ctx context.Context, query string, limit, offset int) ([]entity.OrderSearchResult, int64, error) {
+	var total int64
+
+	countQuery := `
+        SELECT count(*)
+        FROM orders
+        WHERE search_vector @@ websearch_to_tsquery('simple', $1)`
+
+	if err := r.db.QueryRow(ctx, countQuery, query).Scan(&total); err != nil {
+		logger.FromContext(ctx).Error("failed to count search results", zap.Error(err), zap.String("query", query))
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []entity.OrderSearchResult{}, 0, nil
+	}
+
+	searchQuery := `
+        SELECT o.id, o.user_id, o.subtotal, o.discount_amount, o.tax_amount, o.prices_include_tax,
+               o.shipping_method, o.shipping_cost, o.total_amount, o.status, o.shipping_address, o.billing_address, o.notes,
+               o.created_at, o.updated_at,
+               ts_rank(o.search_vector, q.query) AS rank,
+               ts_headline('simple',
+                           concat_ws(' ', o.shipping_addr, p.names, o.notes),
+                           q.query,
+                           'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS highlight
+        FROM orders o
+        CROSS JOIN websearch_to_tsquery('simple', $1) AS q(query)
+        LEFT JOIN LATERAL (
+            SELECT string_agg(pr.name, ' ') AS names
+            FROM order_items oi
+            JOIN products pr ON pr.id = oi.product_id
+            WHERE oi.order_id = o.id
+        ) p ON true
+        WHERE o.search_vector @@ q.query
+        ORDER BY rank DESC, o.id DESC
+        LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, searchQuery, query, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to search orders", zap.Error(err), zap.String("query", query))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := make([]entity.OrderSearchResult, 0, limit)
+	for rows.Next() {
+		var result entity.OrderSearchResult
+		err := rows.Scan(
+			&result.Order.ID,
+			&result.Order.UserID,
+			&result.Order.Subtotal,
+			&result.Order.DiscountAmount,
+			&result.Order.TaxAmount,
+			&result.Order.PricesIncludeTax,
+			&result.Order.ShippingMethod,
+			&result.Order.ShippingCost,
+			&result.Order.TotalAmount,
+			&result.Order.Status,
+			&result.Order.ShippingAddress,
+			&result.Order.BillingAddress,
+			&result.Order.Notes,
+			&result.Order.CreatedAt,
+			&result.Order.UpdatedAt,
+			&result.Rank,
+			&result.Highlight,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan search result", zap.Error(err))
+			return nil, 0, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate search results", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+func (r *orderRepository) ListOrdersByUser(ctx context.Context, userID int64, limit, offset int) ([]entity.UserOrderSummary, int64, error) {
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT count(*) FROM orders WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		logger.FromContext(ctx).Error("failed to count user orders", zap.Error(err), zap.Int64("userId", userID))
+		return nil, 0, err
+	}
+
+	summaries := make([]entity.UserOrderSummary, 0, limit)
+	if total == 0 {
+		return summaries, 0, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT o.id, o.user_id, o.status, coalesce(i.item_count, 0), coalesce(i.line_count, 0), o.total_amount,
+               o.shipping_method, o.created_at, o.updated_at
+        FROM orders o
+        LEFT JOIN LATERAL (
+            SELECT sum(oi.quantity) AS item_count, count(*) AS line_count
+            FROM order_items oi
+            WHERE oi.order_id = o.id
+        ) i ON true
+        WHERE o.user_id = $1
+        ORDER BY o.created_at DESC, o.id DESC
+        LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list user orders", zap.Error(err), zap.Int64("userId", userID))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.UserOrderSummary
+		err := rows.Scan(&s.OrderID, &s.UserID, &s.Status, &s.ItemCount, &s.LineCount, &s.TotalAmount,
+			&s.ShippingMethod, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan user order", zap.Error(err))
+			return nil, 0, err
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, total, rows.Err()
+}
@@ -0,0 +1,183 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type paymentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) *paymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+func (r *paymentRepository) CreatePayment(ctx context.Context, payment *entity.Payment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := listPayments(ctx, tx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := order.CanPay(existing); err != nil {
+		return err
+	}
+
+	payment.Status = entity.PaymentStatusPending
+	err = tx.QueryRow(ctx, `
+        INSERT INTO payments (order_id, amount, provider, payment_method, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        RETURNING id`,
+		payment.OrderID,
+		payment.Amount,
+		payment.Provider,
+		payment.PaymentMethod,
+		payment.Status,
+		payment.CreatedAt,
+	).Scan(&payment.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrPaymentInProgress
+		}
+		logger.FromContext(ctx).Error("failed to insert payment", zap.Error(err), zap.Int64("orderId", payment.OrderID))
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *paymentRepository) UpdatePayment(ctx context.Context, payment *entity.Payment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updatePayment(ctx, tx, payment); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *paymentRepository) CompletePayment(ctx context.Context, payment *entity.Payment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockOrder(ctx, tx, payment.OrderID); err != nil {
+		return err
+	}
+
+	// The events decide whether the order can still be paid
+	aggregate, err := loadOrderAggregate(ctx, tx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+	if aggregate.Order.Status != entity.OrderStatusPending {
+		return entity.ErrOrderNotPayable
+	}
+
+	if err := updatePayment(ctx, tx, payment); err != nil {
+		return err
+	}
+
+	if err := changeOrderStatus(ctx, tx, aggregate, entity.OrderStatusPaid); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *paymentRepository) ListPayments(ctx context.Context, orderID int64) ([]entity.Payment, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, orderID).Scan(&exists); err != nil {
+		logger.FromContext(ctx).Error("failed to check order", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
+	}
+
+	return listPayments(ctx, tx, orderID)
+}
+
+func updatePayment(ctx context.Context, tx pgx.Tx, payment *entity.Payment) error {
+	tag, err := tx.Exec(ctx, `
+        UPDATE payments
+        SET status = $2, authorization_id = $3, capture_id = $4, failure_reason = $5, updated_at = $6
+        WHERE id = $1`,
+		payment.ID,
+		payment.Status,
+		payment.AuthorizationID,
+		payment.CaptureID,
+		payment.FailureReason,
+		payment.UpdatedAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update payment", zap.Error(err), zap.Int64("paymentId", payment.ID))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrPaymentNotFound
+	}
+	return nil
+}
+
+func listPayments(ctx context.Context, tx pgx.Tx, orderID int64) ([]entity.Payment, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT id, order_id, amount, provider, payment_method, status, authorization_id, capture_id,
+               failure_reason, created_at, updated_at
+        FROM payments
+        WHERE order_id = $1
+        ORDER BY id`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get payments", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []entity.Payment{}
+	for rows.Next() {
+		var p entity.Payment
+		err := rows.Scan(&p.ID, &p.OrderID, &p.Amount, &p.Provider, &p.PaymentMethod, &p.Status,
+			&p.AuthorizationID, &p.CaptureID, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan payment", zap.Error(err))
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
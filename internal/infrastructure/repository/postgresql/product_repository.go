@@ -0,0 +1,89 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type productRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProductRepository(db *pgxpool.Pool) *productRepository {
+	return &productRepository{
+		db: db,
+	}
+}
+
+// GetProductsByIDs returns the products with the given IDs. IDs that do not
+// exist are skipped, so callers should compare the result with their input.
+func (r *productRepository) GetProductsByIDs(ctx context.Context, productIDs []int64) ([]entity.Product, error) {
+	query := `
+        SELECT id, name, coalesce(description, ''), price, stock_quantity, weight_grams,
+               coalesce(category, ''), created_at, updated_at
+        FROM products
+        WHERE id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, productIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get products", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]entity.Product, 0, len(productIDs))
+	for rows.Next() {
+		var product entity.Product
+		err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.StockQuantity,
+			&product.WeightGrams,
+			&product.Category,
+			&product.CreatedAt,
+			&product.UpdatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan product", zap.Error(err))
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate products", zap.Error(err))
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// adjustStock changes the stock of a product by delta inside tx. It is the
+// single path through which inventory changes and never lets stock go
+// negative: such a change fails with entity.ErrInsufficientStock.
+func adjustStock(ctx context.Context, tx pgx.Tx, productID int64, delta int) error {
+	tag, err := tx.Exec(ctx, `
+        UPDATE products
+        SET stock_quantity = stock_quantity + $2, updated_at = $3
+        WHERE id = $1 AND stock_quantity + $2 >= 0`,
+		productID, delta, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to adjust stock",
+			zap.Error(err),
+			zap.Int64("productId", productID),
+			zap.Int("delta", delta))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: product %d", entity.ErrInsufficientStock, productID)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+const projectionBatchSize = 500
+
+// Projection builds a read model from order events.
+type Projection interface {
+	// Name identifies the projection's checkpoint.
+	Name() string
+	// Apply projects one event. Events may be delivered more than once.
+	Apply(ctx context.Context, tx pgx.Tx, event entity.OrderEvent) error
+	// Reset clears the read model before a rebuild.
+	Reset(ctx context.Context, tx pgx.Tx) error
+}
+
+// ProjectionWorker feeds order events to a projection in order and records
+// its progress in projection_checkpoints.
+//
+// Event ids are taken when an event is inserted but become visible when its
+// transaction commits, so a lower id may show up after a higher one. The
+// worker therefore stops at a gap in the ids until every transaction that
+// was running when it saw the gap has ended; a gap left by a rolled back
+// transaction is then skipped.
+type ProjectionWorker struct {
+	db         *pgxpool.Pool
+	projection Projection
+	interval   time.Duration
+	gap        *eventGap
+}
+
+// eventGap is a missing id after position. Its event can only be committed
+// by a transaction with an id below horizon.
+type eventGap struct {
+	position int64
+	horizon  int64
+}
+
+func NewProjectionWorker(db *pgxpool.Pool, projection Projection, interval time.Duration) *ProjectionWorker {
+	return &ProjectionWorker{
+		db:         db,
+		projection: projection,
+		interval:   interval,
+	}
+}
+
+func (w *ProjectionWorker) Name() string {
+	return w.projection.Name()
+}
+
+// Run catches up with the event log every interval until ctx is done.
+func (w *ProjectionWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("Projection failed", zap.Error(err), zap.String("projection", w.projection.Name()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CatchUp projects all events recorded after the checkpoint and returns how
+// many were applied.
+func (w *ProjectionWorker) CatchUp(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := w.projectBatch(ctx)
+		total += n
+		if err != nil || n < projectionBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Rebuild resets the read model and replays the whole event log.
+func (w *ProjectionWorker) Rebuild(ctx context.Context) (int, error) {
+	err := pgx.BeginFunc(ctx, w.db, func(tx pgx.Tx) error {
+		if err := w.projection.Reset(ctx, tx); err != nil {
+			return err
+		}
+		return saveCheckpoint(ctx, tx, w.projection.Name(), 0)
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to reset projection", zap.Error(err), zap.String("projection", w.projection.Name()))
+		return 0, err
+	}
+
+	return w.CatchUp(ctx)
+}
+
+func (w *ProjectionWorker) projectBatch(ctx context.Context) (int, error) {
+	applied := 0
+	err := pgx.BeginFunc(ctx, w.db, func(tx pgx.Tx) error {
+		// Locking the checkpoint keeps concurrent workers from applying the
+		// same batch
+		_, err := tx.Exec(ctx, `
+            INSERT INTO projection_checkpoints (name, position)
+            VALUES ($1, 0)
+            ON CONFLICT (name) DO NOTHING`, w.projection.Name())
+		if err != nil {
+			return err
+		}
+
+		var position int64
+		err = tx.QueryRow(ctx, `
+            SELECT position
+            FROM projection_checkpoints
+            WHERE name = $1
+            FOR UPDATE`, w.projection.Name()).Scan(&position)
+		if err != nil {
+			return err
+		}
+
+		// Every transaction below oldest has ended before the events are
+		// read, so a gap recorded with a horizon up to oldest stays a gap
+		var oldest int64
+		err = tx.QueryRow(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`).Scan(&oldest)
+		if err != nil {
+			return err
+		}
+
+		events, err := readOrderEvents(ctx, tx, `WHERE id > $1 ORDER BY id LIMIT $2`, position, projectionBatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		next := position
+		for _, event := range events {
+			if event.ID != next+1 {
+				settled := w.gap != nil && w.gap.position == next && w.gap.horizon <= oldest
+				if !settled {
+					if w.gap == nil || w.gap.position != next {
+						if err := w.recordGap(ctx, tx, next); err != nil {
+							return err
+						}
+					}
+					break
+				}
+				w.gap = nil
+			}
+
+			if err := w.projection.Apply(ctx, tx, event); err != nil {
+				logger.FromContext(ctx).Error("failed to project event",
+					zap.Error(err),
+					zap.String("projection", w.projection.Name()),
+					zap.Int64("eventId", event.ID))
+				return err
+			}
+			next = event.ID
+			applied++
+		}
+
+		if next == position {
+			return nil
+		}
+		return saveCheckpoint(ctx, tx, w.projection.Name(), next)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// recordGap remembers the gap after position. Its event, if any, belongs to
+// a transaction still running and thus with an id below the current xmax;
+// appendOrderEvents makes sure the transaction id is taken before the event
+// id.
+func (w *ProjectionWorker) recordGap(ctx context.Context, tx pgx.Tx, position int64) error {
+	var horizon int64
+	err := tx.QueryRow(ctx, `SELECT pg_snapshot_xmax(pg_current_snapshot())::text::bigint`).Scan(&horizon)
+	if err != nil {
+		return err
+	}
+	w.gap = &eventGap{position: position, horizon: horizon}
+	return nil
+}
+
+func saveCheckpoint(ctx context.Context, tx pgx.Tx, name string, position int64) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO projection_checkpoints (name, position, updated_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (name) DO UPDATE SET position = EXCLUDED.position, updated_at = EXCLUDED.updated_at`,
+		name, position, time.Now())
+	return err
+}
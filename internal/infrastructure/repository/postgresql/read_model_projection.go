@@ -0,0 +1,318 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+// userOrderSummaryProjection maintains user_order_summaries, one row per
+// order with the columns an order history page shows.
+type userOrderSummaryProjection struct{}
+
+func NewUserOrderSummaryProjection() Projection {
+	return userOrderSummaryProjection{}
+}
+
+func (userOrderSummaryProjection) Name() string {
+	return "user_order_summaries"
+}
+
+func (userOrderSummaryProjection) Apply(ctx context.Context, tx pgx.Tx, event entity.OrderEvent) error {
+	switch event.Type {
+	case entity.OrderEventCreated:
+		var order entity.Order
+		if err := json.Unmarshal(event.Data, &order); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+
+		itemCount := 0
+		for _, item := range order.Items {
+			itemCount += item.Quantity
+		}
+
+		_, err := tx.Exec(ctx, `
+            INSERT INTO user_order_summaries (order_id, user_id, status, item_count, line_count, total_amount,
+                                              shipping_method, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            ON CONFLICT (order_id) DO NOTHING`,
+			order.ID,
+			order.UserID,
+			order.Status,
+			itemCount,
+			len(order.Items),
+			order.TotalAmount,
+			order.ShippingMethod,
+			order.CreatedAt,
+			order.UpdatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order summary", zap.Error(err), zap.Int64("orderId", order.ID))
+		}
+		return err
+	case entity.OrderEventStatusChanged:
+		var change entity.OrderStatusChanged
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+
+		_, err := tx.Exec(ctx, `
+            UPDATE user_order_summaries
+            SET status = $2, updated_at = $3
+            WHERE order_id = $1`,
+			event.OrderID, change.To, event.OccurredAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order summary status", zap.Error(err), zap.Int64("orderId", event.OrderID))
+		}
+		return err
+	default:
+		return fmt.Errorf("%w: unknown type %q", entity.ErrInvalidOrderEvent, event.Type)
+	}
+}
+
+func (userOrderSummaryProjection) Reset(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `TRUNCATE user_order_summaries`)
+	return err
+}
+
+// salesProjection maintains daily_sales and daily_category_sales. Orders
+// count towards the day they were placed (UTC) and move between status rows
+// as their status changes. sales_orders and sales_order_facts remember what
+// each order contributed so it can be moved.
+type salesProjection struct{}
+
+func NewSalesProjection() Projection {
+	return salesProjection{}
+}
+
+func (salesProjection) Name() string {
+	return "daily_sales"
+}
+
+func (salesProjection) Apply(ctx context.Context, tx pgx.Tx, event entity.OrderEvent) error {
+	switch event.Type {
+	case entity.OrderEventCreated:
+		var order entity.Order
+		if err := json.Unmarshal(event.Data, &order); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+		return projectOrderSales(ctx, tx, &order)
+	case entity.OrderEventStatusChanged:
+		var change entity.OrderStatusChanged
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidOrderEvent, err)
+		}
+		return moveOrderSales(ctx, tx, event.OrderID, change.To)
+	default:
+		return fmt.Errorf("%w: unknown type %q", entity.ErrInvalidOrderEvent, event.Type)
+	}
+}
+
+func (salesProjection) Reset(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `TRUNCATE sales_orders, sales_order_facts, daily_sales, daily_category_sales`)
+	return err
+}
+
+func projectOrderSales(ctx context.Context, tx pgx.Tx, order *entity.Order) error {
+	day := order.CreatedAt.UTC().Format(time.DateOnly)
+
+	itemCount := 0
+	for _, item := range order.Items {
+		itemCount += item.Quantity
+	}
+
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO sales_orders (order_id, day, status, item_count, revenue)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (order_id) DO NOTHING`,
+		order.ID, day, order.Status, itemCount, order.TotalAmount)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to project order sales", zap.Error(err), zap.Int64("orderId", order.ID))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	if err := addDailySales(ctx, tx, day, order.Status, 1, itemCount, order.TotalAmount); err != nil {
+		return err
+	}
+
+	// Categories are looked up when the order is projected; later changes to
+	// a product's category do not move past sales
+	productIDs := make([]int64, 0, len(order.Items))
+	for _, item := range order.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	categories := make(map[int64]string, len(productIDs))
+	rows, err := tx.Query(ctx, `SELECT id, coalesce(category, '') FROM products WHERE id = ANY($1)`, productIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get product categories", zap.Error(err), zap.Int64("orderId", order.ID))
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		var category string
+		if err := rows.Scan(&id, &category); err != nil {
+			rows.Close()
+			return err
+		}
+		categories[id] = category
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	type fact struct {
+		quantity int
+		revenue  float64
+	}
+	facts := make(map[string]*fact)
+	var categoryOrder []string
+	for _, item := range order.Items {
+		category := categories[item.ProductID]
+		f, ok := facts[category]
+		if !ok {
+			f = &fact{}
+			facts[category] = f
+			categoryOrder = append(categoryOrder, category)
+		}
+		f.quantity += item.Quantity
+		f.revenue += item.TotalPrice
+	}
+
+	for _, category := range categoryOrder {
+		f := facts[category]
+		_, err := tx.Exec(ctx, `
+            INSERT INTO sales_order_facts (order_id, category, day, status, quantity, revenue)
+            VALUES ($1, $2, $3, $4, $5, $6)`,
+			order.ID, category, day, order.Status, f.quantity, f.revenue)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to project order sales facts", zap.Error(err), zap.Int64("orderId", order.ID))
+			return err
+		}
+		if err := addDailyCategorySales(ctx, tx, day, order.Status, category, 1, f.quantity, f.revenue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveOrderSales moves the totals of an order from its previous status row
+// to status.
+func moveOrderSales(ctx context.Context, tx pgx.Tx, orderID int64, status entity.OrderStatus) error {
+	var day string
+	var from entity.OrderStatus
+	var itemCount int
+	var revenue float64
+	err := tx.QueryRow(ctx, `
+        SELECT to_char(day, 'YYYY-MM-DD'), status, item_count, revenue
+        FROM sales_orders
+        WHERE order_id = $1
+        FOR UPDATE`, orderID).Scan(&day, &from, &itemCount, &revenue)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: order %d changed before it was created", entity.ErrInvalidOrderEvent, orderID)
+		}
+		logger.FromContext(ctx).Error("failed to get order sales", zap.Error(err), zap.Int64("orderId", orderID))
+		return err
+	}
+	if from == status {
+		return nil
+	}
+
+	if err := addDailySales(ctx, tx, day, from, -1, -itemCount, -revenue); err != nil {
+		return err
+	}
+	if err := addDailySales(ctx, tx, day, status, 1, itemCount, revenue); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE sales_orders SET status = $2 WHERE order_id = $1`, orderID, status); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT category, quantity, revenue
+        FROM sales_order_facts
+        WHERE order_id = $1`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get order sales facts", zap.Error(err), zap.Int64("orderId", orderID))
+		return err
+	}
+	type fact struct {
+		category string
+		quantity int
+		revenue  float64
+	}
+	var facts []fact
+	for rows.Next() {
+		var f fact
+		if err := rows.Scan(&f.category, &f.quantity, &f.revenue); err != nil {
+			rows.Close()
+			return err
+		}
+		facts = append(facts, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, f := range facts {
+		if err := addDailyCategorySales(ctx, tx, day, from, f.category, -1, -f.quantity, -f.revenue); err != nil {
+			return err
+		}
+		if err := addDailyCategorySales(ctx, tx, day, status, f.category, 1, f.quantity, f.revenue); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE sales_order_facts SET status = $2 WHERE order_id = $1`, orderID, status)
+	return err
+}
+
+func addDailySales(ctx context.Context, tx pgx.Tx, day string, status entity.OrderStatus, orders, items int, revenue float64) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO daily_sales (day, status, order_count, item_count, revenue)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (day, status) DO UPDATE
+            SET order_count = daily_sales.order_count + EXCLUDED.order_count,
+                item_count = daily_sales.item_count + EXCLUDED.item_count,
+                revenue = daily_sales.revenue + EXCLUDED.revenue`,
+		day, status, orders, items, revenue)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update daily sales", zap.Error(err), zap.String("day", day))
+	}
+	return err
+}
+
+func addDailyCategorySales(
+	ctx context.Context,
+	tx pgx.Tx,
+	day string,
+	status entity.OrderStatus,
+	category string,
+	orders, quantity int,
+	revenue float64,
+) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO daily_category_sales (day, status, category, order_count, quantity, revenue)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (day, status, category) DO UPDATE
+            SET order_count = daily_category_sales.order_count + EXCLUDED.order_count,
+                quantity = daily_category_sales.quantity + EXCLUDED.quantity,
+                revenue = daily_category_sales.revenue + EXCLUDED.revenue`,
+		day, status, category, orders, quantity, revenue)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update daily category sales", zap.Error(err), zap.String("day", day))
+	}
+	return err
+}
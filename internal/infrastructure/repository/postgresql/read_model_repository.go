@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+// readModelRepository reads the tables maintained by the read model
+// projections. It never touches the orders tables.
+type readModelRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReadModelRepository(db *pgxpool.Pool) *readModelRepository {
+	return &readModelRepository{
+		db: db,
+	}
+}
+
+func (r *readModelRepository) ListUserOrders(ctx context.Context, userID int64, limit, offset int) ([]entity.UserOrderSummary, int64, error) {
+	var total int64
+	err := r.db.QueryRow(ctx, `SELECT count(*) FROM user_order_summaries WHERE user_id = $1`, userID).Scan(&total)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count user orders", zap.Error(err), zap.Int64("userId", userID))
+		return nil, 0, err
+	}
+
+	summaries := make([]entity.UserOrderSummary, 0, limit)
+	if total == 0 {
+		return summaries, 0, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT order_id, user_id, status, item_count, line_count, total_amount, shipping_method, created_at, updated_at
+        FROM user_order_summaries
+        WHERE user_id = $1
+        ORDER BY created_at DESC, order_id DESC
+        LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list user orders", zap.Error(err), zap.Int64("userId", userID))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.UserOrderSummary
+		err := rows.Scan(&s.OrderID, &s.UserID, &s.Status, &s.ItemCount, &s.LineCount, &s.TotalAmount,
+			&s.ShippingMethod, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan user order", zap.Error(err))
+			return nil, 0, err
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, total, rows.Err()
+}
+
+func (r *readModelRepository) DailySales(ctx context.Context, from, to time.Time) ([]entity.DailySales, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT to_char(day, 'YYYY-MM-DD'), status, order_count, item_count, revenue
+        FROM daily_sales
+        WHERE day BETWEEN $1 AND $2 AND order_count > 0
+        ORDER BY day, status`,
+		from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get daily sales", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []entity.DailySales{}
+	for rows.Next() {
+		var s entity.DailySales
+		if err := rows.Scan(&s.Day, &s.Status, &s.OrderCount, &s.ItemCount, &s.Revenue); err != nil {
+			logger.FromContext(ctx).Error("failed to scan daily sales", zap.Error(err))
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+
+	return sales, rows.Err()
+}
+
+func (r *readModelRepository) DailyCategorySales(ctx context.Context, from, to time.Time) ([]entity.DailyCategorySales, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT to_char(day, 'YYYY-MM-DD'), status, category, order_count, quantity, revenue
+        FROM daily_category_sales
+        WHERE day BETWEEN $1 AND $2 AND order_count > 0
+        ORDER BY day, status, category`,
+		from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get daily category sales", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []entity.DailyCategorySales{}
+	for rows.Next() {
+		var s entity.DailyCategorySales
+		if err := rows.Scan(&s.Day, &s.Status, &s.Category, &s.OrderCount, &s.Quantity, &s.Revenue); err != nil {
+			logger.FromContext(ctx).Error("failed to scan daily category sales", zap.Error(err))
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+
+	return sales, rows.Err()
+}
+
+// ProjectionLag reports every projection with a checkpoint. SecondsBehind is
+// the age of the oldest event the projection has not applied yet.
+func (r *readModelRepository) ProjectionLag(ctx context.Context) ([]entity.ProjectionLag, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT c.name, c.position, count(e.id),
+               coalesce(extract(epoch FROM now() - min(e.recorded_at)), 0)::float8
+        FROM projection_checkpoints c
+        LEFT JOIN order_events e ON e.id > c.position
+        GROUP BY c.name, c.position
+        ORDER BY c.name`)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get projection lag", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	lags := []entity.ProjectionLag{}
+	for rows.Next() {
+		var lag entity.ProjectionLag
+		if err := rows.Scan(&lag.Projection, &lag.Position, &lag.EventsBehind, &lag.SecondsBehind); err != nil {
+			logger.FromContext(ctx).Error("failed to scan projection lag", zap.Error(err))
+			return nil, err
+		}
+		lags = append(lags, lag)
+	}
+
+	return lags, rows.Err()
+}
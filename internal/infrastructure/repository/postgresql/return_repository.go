@@ -0,0 +1,354 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const returnColumns = `id, order_id, user_id, status, comment, resolution_note, restocked, received_at,
+               created_at, updated_at`
+
+type returnRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReturnRepository(db *pgxpool.Pool) *returnRepository {
+	return &returnRepository{
+		db: db,
+	}
+}
+
+func (r *returnRepository) CreateReturn(ctx context.Context, ret *entity.ReturnRequest) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, ret.OrderID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := queryReturns(ctx, tx, `WHERE order_id = $1`, ret.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := order.ValidateReturn(existing, ret); err != nil {
+		return err
+	}
+
+	ret.UserID = order.UserID
+	ret.Status = entity.ReturnStatusRequested
+	err = tx.QueryRow(ctx, `
+        INSERT INTO returns (order_id, user_id, status, comment, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $5)
+        RETURNING id`,
+		ret.OrderID,
+		ret.UserID,
+		ret.Status,
+		ret.Comment,
+		ret.CreatedAt,
+	).Scan(&ret.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to insert return", zap.Error(err), zap.Int64("orderId", ret.OrderID))
+		return err
+	}
+
+	for i := range ret.Items {
+		ret.Items[i].ReturnID = ret.ID
+		err = tx.QueryRow(ctx, `
+            INSERT INTO return_items (return_id, order_item_id, quantity, reason)
+            VALUES ($1, $2, $3, $4)
+            RETURNING id`,
+			ret.ID,
+			ret.Items[i].OrderItemID,
+			ret.Items[i].Quantity,
+			ret.Items[i].Reason,
+		).Scan(&ret.Items[i].ID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to insert return item",
+				zap.Error(err),
+				zap.Int64("returnId", ret.ID),
+				zap.Int64("orderItemId", ret.Items[i].OrderItemID))
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *returnRepository) GetReturn(ctx context.Context, returnID int64) (*entity.ReturnRequest, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	returns, err := queryReturns(ctx, tx, `WHERE id = $1`, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) == 0 {
+		return nil, entity.ErrReturnNotFound
+	}
+
+	return &returns[0], nil
+}
+
+func (r *returnRepository) ListReturns(ctx context.Context, orderID int64) ([]entity.ReturnRequest, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	return queryReturns(ctx, tx, `WHERE order_id = $1`, orderID)
+}
+
+func (r *returnRepository) UpdateReturnStatus(
+	ctx context.Context,
+	ret *entity.ReturnRequest,
+	from entity.ReturnStatus,
+	restock bool,
+) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ret.Restocked = ret.Restocked || restock
+	tag, err := tx.Exec(ctx, `
+        UPDATE returns
+        SET status = $3, resolution_note = $4, restocked = $5, received_at = $6, updated_at = $7
+        WHERE id = $1 AND status = $2`,
+		ret.ID,
+		from,
+		ret.Status,
+		ret.ResolutionNote,
+		ret.Restocked,
+		ret.ReceivedAt,
+		ret.UpdatedAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update return", zap.Error(err), zap.Int64("returnId", ret.ID))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrInvalidReturnTransition
+	}
+
+	if restock {
+		for _, item := range ret.Items {
+			var productID int64
+			err := tx.QueryRow(ctx, `SELECT product_id FROM order_items WHERE id = $1`, item.OrderItemID).Scan(&productID)
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to get returned product", zap.Error(err), zap.Int64("orderItemId", item.OrderItemID))
+				return err
+			}
+			if err := adjustStock(ctx, tx, productID, item.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func queryReturns(ctx context.Context, tx pgx.Tx, where string, arg int64) ([]entity.ReturnRequest, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT `+returnColumns+`
+        FROM returns
+        `+where+`
+        ORDER BY id`, arg)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get returns", zap.Error(err))
+		return nil, err
+	}
+
+	returns := []entity.ReturnRequest{}
+	index := make(map[int64]int)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var ret entity.ReturnRequest
+		err := rows.Scan(&ret.ID, &ret.OrderID, &ret.UserID, &ret.Status, &ret.Comment, &ret.ResolutionNote,
+			&ret.Restocked, &ret.ReceivedAt, &ret.CreatedAt, &ret.UpdatedAt)
+		if err != nil {
+			rows.Close()
+			logger.FromContext(ctx).Error("failed to scan return", zap.Error(err))
+			return nil, err
+		}
+		ret.Items = []entity.ReturnItem{}
+		index[ret.ID] = len(returns)
+		ids = append(ids, ret.ID)
+		returns = append(returns, ret)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate returns", zap.Error(err))
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return returns, nil
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT id, return_id, order_item_id, quantity, reason
+        FROM return_items
+        WHERE return_id = ANY($1)
+        ORDER BY id`, ids)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get return items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.ReturnItem
+		if err := rows.Scan(&item.ID, &item.ReturnID, &item.OrderItemID, &item.Quantity, &item.Reason); err != nil {
+			logger.FromContext(ctx).Error("failed to scan return item", zap.Error(err))
+			return nil, err
+		}
+		i := index[item.ReturnID]
+		returns[i].Items = append(returns[i].Items, item)
+	}
+
+	return returns, rows.Err()
+}
+
+type refundRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefundRepository(db *pgxpool.Pool) *refundRepository {
+	return &refundRepository{
+		db: db,
+	}
+}
+
+func (r *refundRepository) CreateRefund(ctx context.Context, refund *entity.Refund) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := queryRefunds(ctx, tx, `WHERE order_id = $1`, refund.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := order.ValidateRefund(existing, refund); err != nil {
+		return err
+	}
+
+	refund.Status = entity.RefundStatusPending
+	err = tx.QueryRow(ctx, `
+        INSERT INTO refunds (order_id, return_id, amount, shipping_amount, reason, status, reference, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id`,
+		refund.OrderID,
+		refund.ReturnID,
+		refund.Amount,
+		refund.ShippingAmount,
+		refund.Reason,
+		refund.Status,
+		refund.Reference,
+		refund.CreatedAt,
+	).Scan(&refund.ID)
+	if err != nil {
+		if isUniqueViolation(err) && refund.ReturnID != nil {
+			return fmt.Errorf("%w: return %d", entity.ErrReturnAlreadyRefunded, *refund.ReturnID)
+		}
+		logger.FromContext(ctx).Error("failed to insert refund", zap.Error(err), zap.Int64("orderId", refund.OrderID))
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *refundRepository) GetRefund(ctx context.Context, refundID int64) (*entity.Refund, error) {
+	refunds, err := queryRefunds(ctx, r.db, `WHERE id = $1`, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if len(refunds) == 0 {
+		return nil, entity.ErrRefundNotFound
+	}
+	return &refunds[0], nil
+}
+
+func (r *refundRepository) ListRefunds(ctx context.Context, orderID int64) ([]entity.Refund, error) {
+	return queryRefunds(ctx, r.db, `WHERE order_id = $1`, orderID)
+}
+
+func (r *refundRepository) UpdateRefundStatus(ctx context.Context, refund *entity.Refund, from entity.RefundStatus) error {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE refunds
+        SET status = $3, reference = $4, completed_at = $5
+        WHERE id = $1 AND status = $2`,
+		refund.ID,
+		from,
+		refund.Status,
+		refund.Reference,
+		refund.CompletedAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update refund", zap.Error(err), zap.Int64("refundId", refund.ID))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrInvalidRefundTransition
+	}
+	return nil
+}
+
+func queryRefunds(ctx context.Context, q querier, where string, arg int64) ([]entity.Refund, error) {
+	rows, err := q.Query(ctx, `
+        SELECT id, order_id, return_id, amount, shipping_amount, reason, status, reference, created_at, completed_at
+        FROM refunds
+        `+where+`
+        ORDER BY id`, arg)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get refunds", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []entity.Refund{}
+	for rows.Next() {
+		var refund entity.Refund
+		err := rows.Scan(&refund.ID, &refund.OrderID, &refund.ReturnID, &refund.Amount, &refund.ShippingAmount,
+			&refund.Reason, &refund.Status, &refund.Reference, &refund.CreatedAt, &refund.CompletedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan refund", zap.Error(err))
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate refunds", zap.Error(err))
+		return nil, err
+	}
+
+	return refunds, nil
+}
@@ -0,0 +1,89 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type sagaRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSagaRepository(db *pgxpool.Pool) *sagaRepository {
+	return &sagaRepository{
+		db: db,
+	}
+}
+
+func (r *sagaRepository) CreateSaga(ctx context.Context, saga *entity.Saga) error {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO sagas (name, key, status, step, data, error, deadline, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+        RETURNING id`,
+		saga.Name,
+		saga.Key,
+		saga.Status,
+		saga.Step,
+		saga.Data,
+		saga.Error,
+		saga.Deadline,
+		saga.CreatedAt,
+	).Scan(&saga.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrSagaInProgress
+		}
+		logger.FromContext(ctx).Error("failed to insert saga", zap.Error(err), zap.String("name", saga.Name), zap.String("key", saga.Key))
+		return err
+	}
+	return nil
+}
+
+func (r *sagaRepository) SaveSaga(ctx context.Context, saga *entity.Saga) error {
+	saga.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, `
+        UPDATE sagas
+        SET status = $2, step = $3, data = $4, error = $5, updated_at = $6
+        WHERE id = $1`,
+		saga.ID,
+		saga.Status,
+		saga.Step,
+		saga.Data,
+		saga.Error,
+		saga.UpdatedAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to save saga", zap.Error(err), zap.Int64("sagaId", saga.ID))
+	}
+	return err
+}
+
+func (r *sagaRepository) ListUnfinishedSagas(ctx context.Context, updatedBefore time.Time) ([]entity.Saga, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, name, key, status, step, data, error, deadline, created_at, updated_at
+        FROM sagas
+        WHERE status IN ('running', 'compensating') AND updated_at < $1
+        ORDER BY id`, updatedBefore)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get unfinished sagas", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sagas := []entity.Saga{}
+	for rows.Next() {
+		var s entity.Saga
+		err := rows.Scan(&s.ID, &s.Name, &s.Key, &s.Status, &s.Step, &s.Data, &s.Error, &s.Deadline, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan saga", zap.Error(err))
+			return nil, err
+		}
+		sagas = append(sagas, s)
+	}
+
+	return sagas, rows.Err()
+}
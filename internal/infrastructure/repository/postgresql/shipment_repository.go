@@ -0,0 +1,314 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type shipmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewShipmentRepository(db *pgxpool.Pool) *shipmentRepository {
+	return &shipmentRepository{
+		db: db,
+	}
+}
+
+func (r *shipmentRepository) CreateShipment(ctx context.Context, shipment *entity.Shipment) (entity.OrderStatus, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, shipment.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := listShipments(ctx, tx, shipment.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := order.ValidateShipment(existing, shipment); err != nil {
+		return "", err
+	}
+
+	shipment.Status = entity.ShipmentStatusShipped
+	err = tx.QueryRow(ctx, `
+        INSERT INTO shipments (order_id, carrier, tracking_number, status, shipped_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`,
+		shipment.OrderID,
+		shipment.Carrier,
+		shipment.TrackingNumber,
+		shipment.Status,
+		shipment.ShippedAt,
+	).Scan(&shipment.ID, &shipment.CreatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to insert shipment", zap.Error(err), zap.Int64("orderId", shipment.OrderID))
+		return "", err
+	}
+
+	for i := range shipment.Items {
+		shipment.Items[i].ShipmentID = shipment.ID
+		err = tx.QueryRow(ctx, `
+            INSERT INTO shipment_items (shipment_id, order_item_id, quantity)
+            VALUES ($1, $2, $3)
+            RETURNING id`,
+			shipment.ID,
+			shipment.Items[i].OrderItemID,
+			shipment.Items[i].Quantity,
+		).Scan(&shipment.Items[i].ID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to insert shipment item",
+				zap.Error(err),
+				zap.Int64("shipmentId", shipment.ID),
+				zap.Int64("orderItemId", shipment.Items[i].OrderItemID))
+			return "", err
+		}
+	}
+
+	status := order.FulfillmentStatus(append(existing, *shipment))
+	if err := updateOrderStatus(ctx, tx, order.ID, status); err != nil {
+		return "", err
+	}
+
+	return status, tx.Commit(ctx)
+}
+
+func (r *shipmentRepository) ListShipments(ctx context.Context, orderID int64) ([]entity.Shipment, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, orderID).Scan(&exists); err != nil {
+		logger.FromContext(ctx).Error("failed to check order", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
+	}
+
+	return listShipments(ctx, tx, orderID)
+}
+
+func (r *shipmentRepository) MarkShipmentDelivered(
+	ctx context.Context,
+	orderID, shipmentID int64,
+	deliveredAt time.Time,
+) (*entity.Shipment, entity.OrderStatus, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to begin transaction", zap.Error(err))
+		return nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	shipments, err := listShipments(ctx, tx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var shipment *entity.Shipment
+	for i := range shipments {
+		if shipments[i].ID == shipmentID {
+			shipment = &shipments[i]
+		}
+	}
+	if shipment == nil {
+		return nil, "", entity.ErrShipmentNotFound
+	}
+	if shipment.Status == entity.ShipmentStatusDelivered {
+		return nil, "", entity.ErrShipmentDelivered
+	}
+
+	shipment.Status = entity.ShipmentStatusDelivered
+	shipment.DeliveredAt = &deliveredAt
+	_, err = tx.Exec(ctx, `
+        UPDATE shipments
+        SET status = $2, delivered_at = $3
+        WHERE id = $1`,
+		shipment.ID, shipment.Status, deliveredAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update shipment", zap.Error(err), zap.Int64("shipmentId", shipmentID))
+		return nil, "", err
+	}
+
+	status := order.FulfillmentStatus(shipments)
+	if err := updateOrderStatus(ctx, tx, order.ID, status); err != nil {
+		return nil, "", err
+	}
+
+	return shipment, status, tx.Commit(ctx)
+}
+
+func listShipments(ctx context.Context, tx pgx.Tx, orderID int64) ([]entity.Shipment, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT id, order_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at
+        FROM shipments
+        WHERE order_id = $1
+        ORDER BY id`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get shipments", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+
+	shipments := []entity.Shipment{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var s entity.Shipment
+		err := rows.Scan(&s.ID, &s.OrderID, &s.Carrier, &s.TrackingNumber, &s.Status, &s.ShippedAt, &s.DeliveredAt, &s.CreatedAt)
+		if err != nil {
+			rows.Close()
+			logger.FromContext(ctx).Error("failed to scan shipment", zap.Error(err))
+			return nil, err
+		}
+		s.Items = []entity.ShipmentItem{}
+		index[s.ID] = len(shipments)
+		shipments = append(shipments, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to iterate shipments", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT si.id, si.shipment_id, si.order_item_id, si.quantity
+        FROM shipment_items si
+        JOIN shipments s ON s.id = si.shipment_id
+        WHERE s.order_id = $1
+        ORDER BY si.id`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get shipment items", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.ShipmentItem
+		if err := rows.Scan(&item.ID, &item.ShipmentID, &item.OrderItemID, &item.Quantity); err != nil {
+			logger.FromContext(ctx).Error("failed to scan shipment item", zap.Error(err))
+			return nil, err
+		}
+		i := index[item.ShipmentID]
+		shipments[i].Items = append(shipments[i].Items, item)
+	}
+
+	return shipments, rows.Err()
+}
+
+// lockOrder loads an order with its items and discounts and locks the order
+// row until the end of the transaction.
+func lockOrder(ctx context.Context, tx pgx.Tx, orderID int64) (*entity.Order, error) {
+	order := &entity.Order{}
+	err := tx.QueryRow(ctx, `
+        SELECT id, user_id, status, subtotal, discount_amount, shipping_cost, total_amount
+        FROM orders
+        WHERE id = $1
+        FOR UPDATE`, orderID,
+	).Scan(&order.ID, &order.UserID, &order.Status, &order.Subtotal, &order.DiscountAmount,
+		&order.ShippingCost, &order.TotalAmount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
+		}
+		logger.FromContext(ctx).Error("failed to lock order", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT id, product_id, quantity, unit_price, total_price, tax_rate, tax_amount
+        FROM order_items
+        WHERE order_id = $1
+        ORDER BY id`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get order items", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entity.OrderItem{OrderID: orderID}
+		err := rows.Scan(&item.ID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.TotalPrice, &item.TaxRate, &item.TaxAmount)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan order item", zap.Error(err))
+			return nil, err
+		}
+		order.Items = append(order.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT id, coalesce(coupon_id, 0), code, type, description, amount
+        FROM order_discounts
+        WHERE order_id = $1`, orderID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get order discounts", zap.Error(err), zap.Int64("orderId", orderID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		discount := entity.OrderDiscount{OrderID: orderID}
+		err := rows.Scan(&discount.ID, &discount.CouponID, &discount.Code, &discount.Type, &discount.Description, &discount.Amount)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to scan order discount", zap.Error(err))
+			return nil, err
+		}
+		order.Discounts = append(order.Discounts, discount)
+	}
+
+	return order, rows.Err()
+}
+
+// updateOrderStatus records a status change of an order locked by tx as an
+// order_status_changed event. Changing to the current status does nothing.
+func updateOrderStatus(ctx context.Context, tx pgx.Tx, orderID int64, status entity.OrderStatus) error {
+	aggregate, err := loadOrderAggregate(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	return changeOrderStatus(ctx, tx, aggregate, status)
+}
+
+// changeOrderStatus applies the change to the aggregate loaded within tx and
+// appends its event after the ones the aggregate was built from.
+func changeOrderStatus(ctx context.Context, tx pgx.Tx, aggregate *entity.OrderAggregate, status entity.OrderStatus) error {
+	event, err := aggregate.ChangeStatus(status, time.Now())
+	if err != nil || event == nil {
+		return err
+	}
+
+	if err := appendOrderEvents(ctx, tx, aggregate.Order.ID, event.Sequence-1, []entity.OrderEvent{*event}); err != nil {
+		logger.FromContext(ctx).Error("failed to update order status",
+			zap.Error(err),
+			zap.Int64("orderId", aggregate.Order.ID),
+			zap.String("status", string(status)))
+		return err
+	}
+	return nil
+}
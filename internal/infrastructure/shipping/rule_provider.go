@@ -0,0 +1,166 @@
+package shipping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"os"
+	"sort"
+	"strings"
+)
+
+// anyCountry matches every destination in Zone.Countries.
+const anyCountry = "*"
+
+// RateRules is the configuration of the rule-based shipping rate provider.
+type RateRules struct {
+	Methods []Method `json:"methods"`
+}
+
+// Method is a shipping service. Its price depends on the first zone that
+// matches the destination country.
+type Method struct {
+	Code          string `json:"code"`
+	Carrier       string `json:"carrier"`
+	Name          string `json:"name"`
+	EstimatedDays int    `json:"estimated_days"`
+	Zones         []Zone `json:"zones"`
+}
+
+// Zone prices a method for a set of countries. The first tier whose limits
+// fit the cart applies; a cart heavier or more valuable than every tier
+// cannot use the method. Orders worth at least FreeShippingThreshold ship
+// for free when the threshold is set.
+type Zone struct {
+	Countries             []string `json:"countries"`
+	Tiers                 []Tier   `json:"tiers"`
+	FreeShippingThreshold float64  `json:"free_shipping_threshold"`
+}
+
+// Tier is a price bracket. Zero limits mean unlimited.
+type Tier struct {
+	MaxWeightGrams int     `json:"max_weight_grams"`
+	MaxOrderValue  float64 `json:"max_order_value"`
+	Cost           float64 `json:"cost"`
+}
+
+type ruleProvider struct {
+	rules RateRules
+}
+
+// NewRuleProvider returns a ShippingRateProvider driven by weight, value and
+// destination rules.
+func NewRuleProvider(rules RateRules) (service.ShippingRateProvider, error) {
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return &ruleProvider{rules: rules}, nil
+}
+
+// LoadRateRules reads RateRules from a JSON file.
+func LoadRateRules(path string) (RateRules, error) {
+	var rules RateRules
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, fmt.Errorf("failed to read shipping rate file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("failed to unmarshal shipping rates: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (p *ruleProvider) Quote(ctx context.Context, req service.ShippingQuoteRequest) ([]entity.ShippingQuote, error) {
+	country := strings.ToUpper(req.Destination.Country)
+	weight := req.TotalWeightGrams()
+	value := req.TotalValue()
+
+	quotes := make([]entity.ShippingQuote, 0, len(p.rules.Methods))
+	for _, method := range p.rules.Methods {
+		zone, ok := method.zoneFor(country)
+		if !ok {
+			continue
+		}
+
+		cost, ok := zone.cost(weight, value)
+		if !ok {
+			continue
+		}
+
+		quotes = append(quotes, entity.ShippingQuote{
+			Method:        method.Code,
+			Carrier:       method.Carrier,
+			Name:          method.Name,
+			Cost:          cost,
+			EstimatedDays: method.EstimatedDays,
+		})
+	}
+
+	if len(quotes) == 0 {
+		return nil, entity.ErrNoShippingAvailable
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Cost < quotes[j].Cost
+	})
+
+	return quotes, nil
+}
+
+func (m Method) zoneFor(country string) (Zone, bool) {
+	for _, zone := range m.Zones {
+		for _, c := range zone.Countries {
+			if c == anyCountry || strings.EqualFold(c, country) {
+				return zone, true
+			}
+		}
+	}
+	return Zone{}, false
+}
+
+func (z Zone) cost(weightGrams int, value float64) (float64, bool) {
+	for _, tier := range z.Tiers {
+		if tier.MaxWeightGrams > 0 && weightGrams > tier.MaxWeightGrams {
+			continue
+		}
+		if tier.MaxOrderValue > 0 && value > tier.MaxOrderValue {
+			continue
+		}
+
+		if z.FreeShippingThreshold > 0 && value >= z.FreeShippingThreshold {
+			return 0, true
+		}
+		return entity.RoundMoney(tier.Cost), true
+	}
+	return 0, false
+}
+
+func (r RateRules) validate() error {
+	codes := make(map[string]bool, len(r.Methods))
+	for _, method := range r.Methods {
+		if method.Code == "" {
+			return fmt.Errorf("shipping method without code")
+		}
+		if codes[method.Code] {
+			return fmt.Errorf("duplicate shipping method %q", method.Code)
+		}
+		codes[method.Code] = true
+
+		for _, zone := range method.Zones {
+			if len(zone.Countries) == 0 || len(zone.Tiers) == 0 {
+				return fmt.Errorf("shipping method %q has a zone without countries or tiers", method.Code)
+			}
+			for _, tier := range zone.Tiers {
+				if tier.Cost < 0 {
+					return fmt.Errorf("shipping method %q has a negative cost", method.Code)
+				}
+			}
+		}
+	}
+	return nil
+}
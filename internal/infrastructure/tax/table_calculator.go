@@ -0,0 +1,170 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"os"
+	"strings"
+)
+
+// RateTable is the configuration of the table-driven tax calculator.
+type RateTable struct {
+	Default       Jurisdiction   `json:"default"`
+	Jurisdictions []Jurisdiction `json:"jurisdictions"`
+}
+
+// Jurisdiction holds the tax rates of a country, or of a region within it
+// when Region is set. Rates are fractions, e.g. 0.21 for 21%.
+type Jurisdiction struct {
+	Country          string             `json:"country"`
+	Region           string             `json:"region"`
+	Rate             float64            `json:"rate"`
+	CategoryRates    map[string]float64 `json:"category_rates"`
+	PricesIncludeTax bool               `json:"prices_include_tax"`
+}
+
+func (j Jurisdiction) name() string {
+	if j.Country == "" {
+		return "default"
+	}
+	if j.Region == "" {
+		return j.Country
+	}
+	return j.Country + "-" + j.Region
+}
+
+func (j Jurisdiction) rateFor(category string) float64 {
+	if rate, ok := j.CategoryRates[strings.ToLower(category)]; ok {
+		return rate
+	}
+	return j.Rate
+}
+
+type tableCalculator struct {
+	table RateTable
+}
+
+// NewTableCalculator returns a TaxCalculator that looks rates up by
+// destination country and region and by product category.
+func NewTableCalculator(table RateTable) (service.TaxCalculator, error) {
+	if err := table.validate(); err != nil {
+		return nil, err
+	}
+
+	for i := range table.Jurisdictions {
+		j := &table.Jurisdictions[i]
+		j.Country = strings.ToUpper(j.Country)
+		j.Region = strings.ToUpper(j.Region)
+		j.CategoryRates = lowerKeys(j.CategoryRates)
+	}
+	table.Default.CategoryRates = lowerKeys(table.Default.CategoryRates)
+
+	return &tableCalculator{table: table}, nil
+}
+
+// LoadRateTable reads a RateTable from a JSON file.
+func LoadRateTable(path string) (RateTable, error) {
+	var table RateTable
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return table, fmt.Errorf("failed to read tax rate file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &table); err != nil {
+		return table, fmt.Errorf("failed to unmarshal tax rates: %w", err)
+	}
+
+	return table, nil
+}
+
+func (c *tableCalculator) Calculate(ctx context.Context, req service.TaxRequest) (*service.TaxResult, error) {
+	jurisdiction := c.lookup(req.Destination)
+
+	result := &service.TaxResult{
+		Jurisdiction:     jurisdiction.name(),
+		PricesIncludeTax: jurisdiction.PricesIncludeTax,
+		Lines:            make([]service.LineTax, len(req.Lines)),
+	}
+
+	for i, line := range req.Lines {
+		rate := jurisdiction.rateFor(line.Category)
+
+		var tax float64
+		if jurisdiction.PricesIncludeTax {
+			tax = line.Amount - line.Amount/(1+rate)
+		} else {
+			tax = line.Amount * rate
+		}
+		tax = entity.RoundMoney(tax)
+
+		result.Lines[i] = service.LineTax{Rate: rate, TaxAmount: tax}
+		result.TotalTax += tax
+	}
+	result.TotalTax = entity.RoundMoney(result.TotalTax)
+
+	return result, nil
+}
+
+func (c *tableCalculator) lookup(addr entity.Address) Jurisdiction {
+	country := strings.ToUpper(addr.Country)
+	region := strings.ToUpper(addr.Region)
+
+	var countryMatch *Jurisdiction
+	for i := range c.table.Jurisdictions {
+		j := &c.table.Jurisdictions[i]
+		if j.Country != country {
+			continue
+		}
+		if j.Region != "" && j.Region == region {
+			return *j
+		}
+		if j.Region == "" && countryMatch == nil {
+			countryMatch = j
+		}
+	}
+
+	if countryMatch != nil {
+		return *countryMatch
+	}
+	return c.table.Default
+}
+
+func (t RateTable) validate() error {
+	check := func(j Jurisdiction) error {
+		if j.Rate < 0 || j.Rate >= 1 {
+			return fmt.Errorf("invalid tax rate %v for %s", j.Rate, j.name())
+		}
+		for category, rate := range j.CategoryRates {
+			if rate < 0 || rate >= 1 {
+				return fmt.Errorf("invalid tax rate %v for %s category %s", rate, j.name(), category)
+			}
+		}
+		return nil
+	}
+
+	if err := check(t.Default); err != nil {
+		return err
+	}
+	for _, j := range t.Jurisdictions {
+		if j.Country == "" {
+			return fmt.Errorf("tax jurisdiction without country")
+		}
+		if err := check(j); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lowerKeys(rates map[string]float64) map[string]float64 {
+	result := make(map[string]float64, len(rates))
+	for k, v := range rates {
+		result[strings.ToLower(k)] = v
+	}
+	return result
+}
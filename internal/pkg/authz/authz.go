@@ -0,0 +1,82 @@
+// Package authz decides what an authenticated caller may do. Roles map to
+// permissions through a Policy; every use case requires one permission.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+)
+
+var ErrForbidden = errors.New("permission denied")
+
+type Permission string
+
+const (
+	OrdersCreate   Permission = "orders:create"
+	OrdersReadOwn  Permission = "orders:read_own"
+	OrdersRead     Permission = "orders:read"
+	OrdersPay      Permission = "orders:pay"
+	ShipmentsRead  Permission = "shipments:read"
+	ShipmentsWrite Permission = "shipments:write"
+	ReturnsRequest Permission = "returns:request"
+	ReturnsRead    Permission = "returns:read"
+	ReturnsReview  Permission = "returns:review"
+	ReturnsReceive Permission = "returns:receive"
+	RefundsRead    Permission = "refunds:read"
+	RefundsIssue   Permission = "refunds:issue"
+	CouponsManage  Permission = "coupons:manage"
+	ShippingQuote  Permission = "shipping:quote"
+	ReportsRead    Permission = "reports:read"
+	APIKeysManage  Permission = "api_keys:manage"
+	LoggingManage  Permission = "logging:manage"
+)
+
+// Permissions lists every permission a policy may grant.
+var Permissions = []Permission{
+	OrdersCreate, OrdersReadOwn, OrdersRead, OrdersPay,
+	ShipmentsRead, ShipmentsWrite,
+	ReturnsRequest, ReturnsRead, ReturnsReview, ReturnsReceive,
+	RefundsRead, RefundsIssue,
+	CouponsManage, ShippingQuote, ReportsRead,
+	APIKeysManage, LoggingManage,
+}
+
+// ForbiddenError names the permission the caller is missing.
+type ForbiddenError struct {
+	Permission Permission
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s: missing %s", ErrForbidden, e.Permission)
+}
+
+func (e *ForbiddenError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Require returns nil when the caller in ctx was granted p, a
+// *ForbiddenError when not, and identity.ErrUnauthenticated without a
+// caller.
+func Require(ctx context.Context, p Permission) error {
+	id, ok := identity.FromContext(ctx)
+	if !ok {
+		return identity.ErrUnauthenticated
+	}
+	if !id.Can(string(p)) {
+		return &ForbiddenError{Permission: p}
+	}
+	return nil
+}
+
+// CanAccess reports whether the caller in ctx is the user ownerID or was
+// granted p, the permission to act on everyone's resources. Use cases
+// report resources failing it as not found, so their ids cannot be probed.
+func CanAccess(ctx context.Context, ownerID int64, p Permission) bool {
+	id, ok := identity.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	return id.Can(string(p)) || (id.UserID > 0 && id.UserID == ownerID)
+}
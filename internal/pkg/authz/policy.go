@@ -0,0 +1,68 @@
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Wildcard grants every permission.
+const Wildcard = "*"
+
+// Policy maps roles, e.g. customer, support, warehouse and admin, to the
+// permissions they grant. Roles missing from the policy grant nothing.
+type Policy struct {
+	Roles map[string][]string `json:"roles"`
+}
+
+// LoadPolicy reads a Policy from a JSON file and validates it.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization policy file: %w", err)
+	}
+
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorization policy: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate rejects unknown permissions so a typo cannot silently lock
+// everyone out.
+func (p *Policy) Validate() error {
+	for role, permissions := range p.Roles {
+		for _, permission := range permissions {
+			if permission != Wildcard && !slices.Contains(Permissions, Permission(permission)) {
+				return fmt.Errorf("role %q grants unknown permission %q", role, permission)
+			}
+		}
+	}
+	return nil
+}
+
+// Grants returns the permissions of all the given roles plus the scopes
+// granted directly, expanding the wildcard.
+func (p *Policy) Grants(roles, scopes []string) []string {
+	granted := slices.Clone(scopes)
+	for _, role := range roles {
+		for _, permission := range p.Roles[role] {
+			if permission == Wildcard {
+				granted = granted[:0]
+				for _, all := range Permissions {
+					granted = append(granted, string(all))
+				}
+				return granted
+			}
+			granted = append(granted, permission)
+		}
+	}
+	slices.Sort(granted)
+	return slices.Compact(granted)
+}
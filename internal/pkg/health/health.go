@@ -0,0 +1,131 @@
+// Package health reports whether the service can take traffic: it has
+// started, is not shutting down and reaches its dependencies.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check verifies one dependency, e.g. by pinging it.
+type Check func(ctx context.Context) error
+
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusStarting = "starting"
+	StatusDraining = "draining"
+
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// Report is the readiness of the service and of each dependency.
+type Report struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyReport `json:"dependencies,omitempty"`
+}
+
+// DependencyReport is the result of one Check. Err is not serialized; the
+// report is served without authentication.
+type DependencyReport struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Err       error   `json:"-"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks of the dependencies, each with its own timeout.
+// It reports starting until MarkStarted and draining after MarkDraining.
+type Checker struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	started  atomic.Bool
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers the check of a dependency. Call it before serving requests.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// MarkStarted ends the startup phase, e.g. once migrations are applied.
+func (c *Checker) MarkStarted() {
+	c.started.Store(true)
+}
+
+func (c *Checker) Started() bool {
+	return c.started.Load()
+}
+
+// MarkDraining reports the service not ready from now on, so load balancers
+// stop sending requests before the server shuts down.
+func (c *Checker) MarkDraining() {
+	c.draining.Store(true)
+}
+
+// Check runs all checks concurrently. Dependencies are only checked once
+// the service has started and while it is not draining.
+func (c *Checker) Check(ctx context.Context) Report {
+	switch {
+	case c.draining.Load():
+		return Report{Status: StatusDraining}
+	case !c.started.Load():
+		return Report{Status: StatusStarting}
+	}
+
+	report := Report{
+		Status:       StatusReady,
+		Dependencies: make(map[string]DependencyReport, len(c.checks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			result := c.run(ctx, nc.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[nc.name] = result
+			if result.Status != DependencyUp {
+				report.Status = StatusNotReady
+			}
+		}(nc)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) DependencyReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := DependencyReport{
+		Status:    DependencyUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Err:       err,
+	}
+	if err != nil {
+		result.Status = DependencyDown
+	}
+	return result
+}
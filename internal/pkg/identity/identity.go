@@ -0,0 +1,54 @@
+// Package identity carries the authenticated caller through request
+// contexts.
+package identity
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+var ErrUnauthenticated = errors.New("caller is not authenticated")
+
+// Identity is the authenticated caller of a request. UserID is the subject
+// parsed as a user id, or zero when the subject is not a user, e.g. a
+// service calling with an API key. Scopes are permissions granted to the
+// credential itself; Permissions adds the ones the authorization policy
+// grants to Roles.
+type Identity struct {
+	Subject     string
+	UserID      int64
+	Roles       []string
+	Scopes      []string
+	Permissions []string
+}
+
+func (id *Identity) HasRole(role string) bool {
+	return slices.Contains(id.Roles, role)
+}
+
+func (id *Identity) Can(permission string) bool {
+	return slices.Contains(id.Permissions, permission)
+}
+
+type contextKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the caller stored in ctx, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(*Identity)
+	return id, ok && id != nil
+}
+
+// UserID returns the id of the authenticated user, or ErrUnauthenticated.
+func UserID(ctx context.Context) (int64, error) {
+	id, ok := FromContext(ctx)
+	if !ok || id.UserID <= 0 {
+		return 0, ErrUnauthenticated
+	}
+	return id.UserID, nil
+}
@@ -6,7 +6,13 @@ type LoggerConfig struct {
 }
 
 type LoggerEnvironmentConfig struct {
-	Level            string              `json:"level"`
+	Level string `json:"level"`
+	// Components override Level for the code under a package path, e.g.
+	// {"repository/postgresql": "debug"}
+	Components       map[string]string   `json:"components"`
+	Sampling         *SamplingConfig     `json:"sampling"`
+	Redaction        RedactionConfig     `json:"redaction"`
+	Rotation         *RotationConfig     `json:"rotation"`
 	Encoding         string              `json:"encoding"`
 	OutputPaths      []string            `json:"outputPaths"`
 	ErrorOutputPaths []string            `json:"errorOutputPaths"`
@@ -14,11 +20,29 @@ type LoggerEnvironmentConfig struct {
 }
 
 type LoggerEncoderConfig struct {
-	MessageKey    string `json:"messageKey"`
-	LevelKey      string `json:"levelKey"`
-	LevelEncoder  string `json:"levelEncoder"`
-	TimeKey       string `json:"timeKey"`
-	TimeEncoder   string `json:"timeEncoder"`
-	CallerKey     string `json:"callerKey"`
+	MessageKey   string `json:"messageKey"`
+	LevelKey     string `json:"levelKey"`
+	LevelEncoder string `json:"levelEncoder"`
+	TimeKey      string `json:"timeKey"`
+	// TimeEncoder is iso8601 (default), rfc3339, rfc3339nano, epoch,
+	// epochMillis or epochNanos
+	TimeEncoder string `json:"timeEncoder"`
+	CallerKey   string `json:"callerKey"`
+	// CallerEncoder is short (default) or full
 	CallerEncoder string `json:"callerEncoder"`
 }
+
+// SamplingConfig caps repeated entries: per second, the first Initial
+// entries with the same level and message are written, then every
+// Thereafter-th one.
+type SamplingConfig struct {
+	Initial    int `json:"initial"`
+	Thereafter int `json:"thereafter"`
+}
+
+func (c *LoggerConfig) forEnvironment(environment string) LoggerEnvironmentConfig {
+	if environment == "production" {
+		return c.Production
+	}
+	return c.Development
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx whose logger adds fields to the ones
+// already attached along the call chain, e.g. the request id.
+func WithContext(ctx context.Context, fields ...zapcore.Field) context.Context {
+	return context.WithValue(ctx, contextKey{}, attached(ctx).With(fields...))
+}
+
+// FromContext returns the logger attached to ctx, or the global logger when
+// there is none. Within a trace its entries carry the trace and span id.
+func FromContext(ctx context.Context) *zap.Logger {
+	l := attached(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return l.With(
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return l
+}
+
+// attached returns the logger of ctx without the trace fields, which change
+// with every span.
+func attached(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	if base == nil {
+		return zap.NewNop()
+	}
+	return base
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// Levels is the verbosity of the logger. Components override Level for the
+// code of one package tree, keyed by a path such as repository/postgresql.
+type Levels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// componentLevel is the level of the source files under path.
+type componentLevel struct {
+	path  string
+	level zapcore.Level
+}
+
+// levelState holds the levels that can change while the service runs.
+type levelState struct {
+	global     zap.AtomicLevel
+	components atomic.Pointer[[]componentLevel]
+}
+
+var levels = newLevelState()
+
+func newLevelState() *levelState {
+	s := &levelState{global: zap.NewAtomicLevel()}
+	s.components.Store(&[]componentLevel{})
+	return s
+}
+
+// GetLevels returns the levels in effect.
+func GetLevels() Levels {
+	l := Levels{Level: levels.global.Level().String()}
+	for _, c := range *levels.components.Load() {
+		if l.Components == nil {
+			l.Components = make(map[string]string)
+		}
+		l.Components[c.path] = c.level.String()
+	}
+	return l
+}
+
+// SetLevels replaces the levels in effect. Errors wrap ErrInvalidLevel.
+func SetLevels(l Levels) error {
+	global, err := zapcore.ParseLevel(l.Level)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidLevel, l.Level)
+	}
+
+	components := make([]componentLevel, 0, len(l.Components))
+	for path, name := range l.Components {
+		level, err := zapcore.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("%w: %q for %s", ErrInvalidLevel, name, path)
+		}
+		path = strings.Trim(path, "/")
+		if path == "" {
+			return fmt.Errorf("%w: empty component path", ErrInvalidLevel)
+		}
+		components = append(components, componentLevel{path: path, level: level})
+	}
+	// Longest paths first, so the most specific component wins
+	slices.SortFunc(components, func(a, b componentLevel) int {
+		return len(b.path) - len(a.path)
+	})
+
+	levels.global.SetLevel(global)
+	levels.components.Store(&components)
+	return nil
+}
+
+// ReloadLevels applies the levels of the logger config file again, e.g. on
+// SIGHUP. Other settings only change on restart.
+func ReloadLevels() error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	cfg := conf.forEnvironment(environment)
+	return SetLevels(Levels{Level: cfg.Level, Components: cfg.Components})
+}
+
+// min is the lowest level any component logs at.
+func (s *levelState) min() zapcore.Level {
+	lowest := s.global.Level()
+	for _, c := range *s.components.Load() {
+		lowest = min(lowest, c.level)
+	}
+	return lowest
+}
+
+// enabled reports whether an entry logged from file at lvl is written.
+func (s *levelState) enabled(file string, lvl zapcore.Level) bool {
+	level := s.global.Level()
+	for _, c := range *s.components.Load() {
+		if strings.Contains(file, "/"+c.path+"/") {
+			level = c.level
+			break
+		}
+	}
+	return level.Enabled(lvl)
+}
+
+// levelCore filters entries by the level of the component that logged them.
+// The caller is only known once the entry is written, so Check lets through
+// everything some component may want and Write does the filtering, before
+// the wrapped core, e.g. a sampler, sees the entry.
+type levelCore struct {
+	inner  zapcore.Core
+	levels *levelState
+}
+
+func newLevelCore(inner zapcore.Core, levels *levelState) zapcore.Core {
+	return &levelCore{inner: inner, levels: levels}
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.min().Enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{inner: c.inner.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *levelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.levels.enabled(ent.Caller.File, ent.Level) {
+		return nil
+	}
+	if checked := c.inner.Check(ent, nil); checked != nil {
+		checked.Write(fields...)
+	}
+	return nil
+}
+
+func (c *levelCore) Sync() error {
+	return c.inner.Sync()
+}
@@ -1,23 +1,40 @@
 package logger
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/Sinet2000/Martix-Orders-Go/configs"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// defaultConfigPath is relative to the working directory
+const defaultConfigPath = "configs/logger_config.json"
+
 var (
-	log     *zap.Logger
-	once    sync.Once
-	logConf *LoggerConfig
+	// base reports the caller of its methods; log skips the package
+	// functions wrapping it
+	base        *zap.Logger
+	log         *zap.Logger
+	once        sync.Once
+	environment string
+	// observing is set while ObserveGlobal replaces the loggers
+	observing bool
 )
 
 // InitLogger initializes the logger with configuration from the JSON file
 func InitLogger(environment string) error {
+	if observing {
+		return nil
+	}
+
 	var err error
 	once.Do(func() {
 		err = setupLogger(environment)
@@ -25,18 +42,24 @@ func InitLogger(environment string) error {
 	return err
 }
 
-func setupLogger(environment string) error {
+func setupLogger(env string) error {
 	// Load configuration
-	if err := loadConfig(); err != nil {
+	logConf, err := readConfig()
+	if err != nil {
 		return fmt.Errorf("failed to load logger config: %w", err)
 	}
 
 	// Choose configuration based on environment
-	var cfg LoggerEnvironmentConfig
-	if environment == "production" {
-		cfg = logConf.Production
-	} else {
-		cfg = logConf.Development
+	environment = env
+	cfg := logConf.forEnvironment(environment)
+
+	if err := SetLevels(Levels{Level: cfg.Level, Components: cfg.Components}); err != nil {
+		return err
+	}
+
+	fieldRedactor, err := newRedactor(cfg.Redaction)
+	if err != nil {
+		return err
 	}
 
 	// Create basic encoder config
@@ -49,46 +72,72 @@ func setupLogger(environment string) error {
 		FunctionKey:    zapcore.OmitKey,
 		StacktraceKey:  "stacktrace",
 		EncodeLevel:    getEncoderByName(cfg.EncoderConfig.LevelEncoder),
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeTime:     getTimeEncoderByName(cfg.EncoderConfig.TimeEncoder),
+		EncodeCaller:   getCallerEncoderByName(cfg.EncoderConfig.CallerEncoder),
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 	}
 
-	// Create Zap configuration
-	zapConfig := zap.Config{
-		Level:            getLogLevel(cfg.Level),
-		Development:      environment != "production",
-		Encoding:         cfg.Encoding,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      cfg.OutputPaths,
-		ErrorOutputPaths: cfg.ErrorOutputPaths,
+	var encoder zapcore.Encoder
+	switch cfg.Encoding {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return fmt.Errorf("unknown log encoding %q", cfg.Encoding)
 	}
 
-	// Build the logger
-	logger, err := zapConfig.Build(
-		zap.AddCallerSkip(1),
-		zap.AddStacktrace(zapcore.ErrorLevel),
-	)
+	output, err := openOutputs(cfg.OutputPaths, cfg.Rotation)
+	if err != nil {
+		return err
+	}
+	errorOutput, err := openOutputs(cfg.ErrorOutputPaths, cfg.Rotation)
 	if err != nil {
-		return fmt.Errorf("failed to build logger: %w", err)
+		return err
 	}
 
-	log = logger
+	// The levels are applied by levelCore, so the inner core lets
+	// everything through
+	core := zapcore.NewCore(encoder, output, zapcore.DebugLevel)
+	if cfg.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+	}
+	core = newLevelCore(newRedactCore(core, fieldRedactor), levels)
+
+	options := []zap.Option{
+		zap.ErrorOutput(errorOutput),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	}
+	if environment != "production" {
+		options = append(options, zap.Development())
+	}
+	logger := zap.New(core, options...)
+
+	base = logger
+	log = logger.WithOptions(zap.AddCallerSkip(1))
 	return nil
 }
 
-func loadConfig() error {
-	data, err := os.ReadFile("configs/logger_config.json")
+// readConfig reads the file named by LOGGER_CONFIG, else defaultConfigPath.
+// Without LOGGER_CONFIG a missing file is not an error; the config embedded
+// in the binary is used instead.
+func readConfig() (*LoggerConfig, error) {
+	path := os.Getenv("LOGGER_CONFIG")
+	data, err := os.ReadFile(cmp.Or(path, defaultConfigPath))
 	if err != nil {
-		return fmt.Errorf("failed to read logger config file: %w", err)
+		if path != "" || !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read logger config file: %w", err)
+		}
+		data = configs.Logger
 	}
 
-	logConf = &LoggerConfig{}
+	logConf := &LoggerConfig{}
 	if err := json.Unmarshal(data, logConf); err != nil {
-		return fmt.Errorf("failed to unmarshal logger config: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal logger config: %w", err)
 	}
 
-	return nil
+	return logConf, nil
 }
 
 func getEncoderByName(encoder string) zapcore.LevelEncoder {
@@ -102,19 +151,28 @@ func getEncoderByName(encoder string) zapcore.LevelEncoder {
 	}
 }
 
-func getLogLevel(level string) zap.AtomicLevel {
-	switch level {
-	case "debug":
-		return zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	case "info":
-		return zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	case "warn":
-		return zap.NewAtomicLevelAt(zapcore.WarnLevel)
-	case "error":
-		return zap.NewAtomicLevelAt(zapcore.ErrorLevel)
+func getTimeEncoderByName(encoder string) zapcore.TimeEncoder {
+	switch encoder {
+	case "rfc3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "epoch":
+		return zapcore.EpochTimeEncoder
+	case "epochMillis":
+		return zapcore.EpochMillisTimeEncoder
+	case "epochNanos":
+		return zapcore.EpochNanosTimeEncoder
 	default:
-		return zap.NewAtomicLevelAt(zapcore.InfoLevel)
+		return zapcore.ISO8601TimeEncoder
+	}
+}
+
+func getCallerEncoderByName(encoder string) zapcore.CallerEncoder {
+	if encoder == "full" {
+		return zapcore.FullCallerEncoder
 	}
+	return zapcore.ShortCallerEncoder
 }
 
 // Logging methods
@@ -140,7 +198,7 @@ func Fatal(msg string, fields ...zapcore.Field) {
 
 // WithFields adds fields to the logger
 func WithFields(fields ...zapcore.Field) *zap.Logger {
-	return log.With(fields...)
+	return base.With(fields...)
 }
 
 // Sync flushes any buffered log entries
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Observe returns a copy of ctx whose logger records the entries at level or
+// above in memory instead of writing them, so tests can assert on what the
+// code under test logged through FromContext.
+func Observe(ctx context.Context, level zapcore.Level) (context.Context, *observer.ObservedLogs) {
+	core, logs := observer.New(level)
+	return context.WithValue(ctx, contextKey{}, zap.New(core)), logs
+}
+
+// ObserveGlobal records the entries of the package functions, e.g. Info, and
+// of contexts without a logger until restore is called. InitLogger does
+// nothing until then. Tests using it must not run in parallel.
+func ObserveGlobal(level zapcore.Level) (logs *observer.ObservedLogs, restore func()) {
+	core, logs := observer.New(level)
+	prevBase, prevLog, prevObserving := base, log, observing
+	base = zap.New(core)
+	log = base.WithOptions(zap.AddCallerSkip(1))
+	observing = true
+
+	return logs, func() {
+		base, log, observing = prevBase, prevLog, prevObserving
+	}
+}
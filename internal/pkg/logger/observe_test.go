@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestObserveGlobalLeavesInitLoggerToRun(t *testing.T) {
+	logs, restore := ObserveGlobal(zapcore.InfoLevel)
+
+	if err := InitLogger("production"); err != nil {
+		t.Fatalf("InitLogger while observing: %v", err)
+	}
+	Info("Observed")
+	if logs.FilterMessage("Observed").Len() != 1 {
+		t.Fatal("expected the entry to be observed")
+	}
+
+	restore()
+
+	ran := false
+	once.Do(func() { ran = true })
+	if !ran {
+		t.Error("ObserveGlobal kept InitLogger from setting up the logger after restore")
+	}
+}
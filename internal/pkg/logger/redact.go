@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue replaces the value of masked fields.
+const RedactedValue = "[REDACTED]"
+
+// minSaltLength is the shortest LOG_REDACTION_SALT accepted. A short or
+// missing salt lets anyone hash candidate values, e.g. all user ids, and
+// match them against the log.
+const minSaltLength = 16
+
+// RedactionConfig lists field keys whose values must not reach the log. A
+// key matches a field with the same key or ending in _<key>, so "address"
+// covers shipping_address and billing_address. Keys are compared in snake
+// case, so "user_id" also covers userId and customerUserID. Drop wins over Hash, and Hash
+// over Mask. Only top level fields are matched; entities are logged with
+// their Redacted types, e.g. entity.RedactedOrder.
+type RedactionConfig struct {
+	// Mask replaces the value with RedactedValue.
+	Mask []string `json:"mask"`
+	// Hash replaces the value with a salted hash, so entries of the same
+	// user can still be correlated. The salt comes from LOG_REDACTION_SALT,
+	// which must then be at least 16 bytes long.
+	Hash []string `json:"hash"`
+	// Drop removes the field.
+	Drop []string `json:"drop"`
+}
+
+type redaction int
+
+const (
+	keep redaction = iota
+	mask
+	hash
+	drop
+)
+
+// redactor decides what happens to each field key.
+type redactor struct {
+	rules map[string]redaction
+	salt  []byte
+}
+
+func newRedactor(cfg RedactionConfig) (*redactor, error) {
+	r := &redactor{
+		rules: make(map[string]redaction),
+		salt:  []byte(os.Getenv("LOG_REDACTION_SALT")),
+	}
+	for _, list := range []struct {
+		keys   []string
+		action redaction
+	}{{cfg.Mask, mask}, {cfg.Hash, hash}, {cfg.Drop, drop}} {
+		for _, key := range list.keys {
+			key = snakeCase(key)
+			r.rules[key] = max(r.rules[key], list.action)
+		}
+	}
+
+	if len(cfg.Hash) > 0 && len(r.salt) < minSaltLength {
+		return nil, fmt.Errorf("LOG_REDACTION_SALT must be at least %d bytes long to hash log fields", minSaltLength)
+	}
+	return r, nil
+}
+
+func (r *redactor) empty() bool {
+	return len(r.rules) == 0
+}
+
+func (r *redactor) action(key string) redaction {
+	key = snakeCase(key)
+	action := r.rules[key]
+	for rule, a := range r.rules {
+		if a > action && strings.HasSuffix(key, "_"+rule) {
+			action = a
+		}
+	}
+	return action
+}
+
+// snakeCase lowers key and separates its camel case words with
+// underscores: userId and userID become user_id, HTTPStatus http_status.
+func snakeCase(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' &&
+			(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// redact returns fields with the configured ones masked, hashed or dropped.
+// The slice is only copied when a field changes.
+func (r *redactor) redact(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	copied := false
+	for i := 0; i < len(out); i++ {
+		action := r.action(out[i].Key)
+		if action == keep {
+			continue
+		}
+		if !copied {
+			out = append([]zapcore.Field(nil), out...)
+			copied = true
+		}
+
+		switch action {
+		case mask:
+			out[i] = zap.String(out[i].Key, RedactedValue)
+		case hash:
+			out[i] = zap.String(out[i].Key, r.hash(out[i]))
+		case drop:
+			out = append(out[:i], out[i+1:]...)
+			i--
+		}
+	}
+	return out
+}
+
+func (r *redactor) hash(f zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+
+	mac := hmac.New(sha256.New, r.salt)
+	fmt.Fprint(mac, enc.Fields[f.Key])
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// redactCore applies a redactor to every field before the wrapped core
+// encodes it.
+type redactCore struct {
+	inner    zapcore.Core
+	redactor *redactor
+}
+
+func newRedactCore(inner zapcore.Core, r *redactor) zapcore.Core {
+	if r.empty() {
+		return inner
+	}
+	return &redactCore{inner: inner, redactor: r}
+}
+
+func (c *redactCore) Enabled(lvl zapcore.Level) bool {
+	return c.inner.Enabled(lvl)
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{inner: c.inner.With(c.redactor.redact(fields)), redactor: c.redactor}
+}
+
+// Check asks the wrapped core, e.g. a sampler, but keeps itself in the
+// entry so that Write sees the fields first.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.inner.Check(ent, nil) != nil {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.inner.Write(ent, c.redactor.redact(fields))
+}
+
+func (c *redactCore) Sync() error {
+	return c.inner.Sync()
+}
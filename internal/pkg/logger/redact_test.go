@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Sinet2000/Martix-Orders-Go/configs"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"user_id", "user_id"},
+		{"userId", "user_id"},
+		{"userID", "user_id"},
+		{"UserId", "user_id"},
+		{"customerUserId", "customer_user_id"},
+		{"HTTPStatus", "http_status"},
+		{"clientIP", "client_ip"},
+		{"shipping_Address", "shipping_address"},
+	}
+
+	for _, tt := range tests {
+		if got := snakeCase(tt.key); got != tt.want {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestProductionRedaction(t *testing.T) {
+	t.Setenv("LOG_REDACTION_SALT", "0123456789abcdef")
+
+	logConf := &LoggerConfig{}
+	if err := json.Unmarshal(configs.Logger, logConf); err != nil {
+		t.Fatalf("unmarshal embedded config: %v", err)
+	}
+	r, err := newRedactor(logConf.Production.Redaction)
+	if err != nil {
+		t.Fatalf("newRedactor: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want redaction
+	}{
+		{"user_id", hash},
+		{"userId", hash},
+		{"customer_user_id", hash},
+		{"customerUserId", hash},
+		{"clientIP", hash},
+		{"email", mask},
+		{"customerEmail", mask},
+		{"shippingAddress", drop},
+		{"orderId", keep},
+	}
+	for _, tt := range tests {
+		if got := r.action(tt.key); got != tt.want {
+			t.Errorf("action(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	fields := r.redact([]zapcore.Field{zap.Int64("user_id", 42), zap.Int64("userId", 42)})
+	snake, camel := fieldString(fields[0]), fieldString(fields[1])
+	if snake == "42" || snake != camel {
+		t.Errorf("user_id hashed to %q and userId to %q, want the same hash", snake, camel)
+	}
+}
+
+func fieldString(f zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	s, _ := enc.Fields[f.Key].(string)
+	return s
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotationConfig rotates the files among the output paths. A file is rotated
+// once it grows past MaxSizeMB (100 when 0) and, if Interval is set, every
+// Interval, e.g. "24h". Rotated files are gzipped when Compress is set and
+// removed when older than MaxAgeDays or beyond the newest MaxBackups; 0
+// keeps them.
+type RotationConfig struct {
+	MaxSizeMB  int    `json:"maxSizeMB"`
+	MaxAgeDays int    `json:"maxAgeDays"`
+	MaxBackups int    `json:"maxBackups"`
+	Compress   bool   `json:"compress"`
+	Interval   string `json:"interval"`
+}
+
+// openOutputs opens the paths of an output as one WriteSyncer. stdout and
+// stderr are never rotated, files only when rotation is configured.
+func openOutputs(paths []string, rotation *RotationConfig) (zapcore.WriteSyncer, error) {
+	var interval time.Duration
+	if rotation != nil {
+		var err error
+		if interval, err = rotation.interval(); err != nil {
+			return nil, err
+		}
+	}
+
+	var syncers []zapcore.WriteSyncer
+	var plain []string
+	for _, path := range paths {
+		if rotation == nil || path == "stdout" || path == "stderr" {
+			plain = append(plain, path)
+			continue
+		}
+		syncers = append(syncers, zapcore.AddSync(rotation.open(path, interval)))
+	}
+	if len(plain) > 0 {
+		ws, _, err := zap.Open(plain...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log outputs: %w", err)
+		}
+		syncers = append(syncers, ws)
+	}
+	return zap.CombineWriteSyncers(syncers...), nil
+}
+
+// OpenRotating opens path for appending, rotated like the log files. Other
+// packages use it for files of their own, e.g. exported spans.
+func OpenRotating(path string, rotation RotationConfig) (io.WriteCloser, error) {
+	interval, err := rotation.interval()
+	if err != nil {
+		return nil, err
+	}
+	return rotation.open(path, interval), nil
+}
+
+func (c *RotationConfig) interval() (time.Duration, error) {
+	if c.Interval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid rotation interval %q", c.Interval)
+	}
+	return interval, nil
+}
+
+func (c *RotationConfig) open(path string, interval time.Duration) *lumberjack.Logger {
+	file := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    c.MaxSizeMB,
+		MaxAge:     c.MaxAgeDays,
+		MaxBackups: c.MaxBackups,
+		Compress:   c.Compress,
+		LocalTime:  true,
+	}
+	if interval > 0 {
+		go rotateEvery(file, interval)
+	}
+	return file
+}
+
+// rotateEvery rotates file for the lifetime of the process, unless nothing
+// was written since the last rotation. Failures go to stderr, like the
+// internal errors of zap itself.
+func rotateEvery(file *lumberjack.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if info, err := os.Stat(file.Filename); err != nil || info.Size() == 0 {
+			continue
+		}
+		if err := file.Rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v failed to rotate %s: %v\n", time.Now(), file.Filename, err)
+		}
+	}
+}
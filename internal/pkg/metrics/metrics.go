@@ -0,0 +1,93 @@
+// Package metrics collects the Prometheus metrics of the service. They are
+// registered on a registry of their own and served by Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ordersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "orders_created_total",
+		Help: "Orders created, by the status they were created with.",
+	}, []string{"status"})
+
+	orderValue = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "order_value",
+		Help:    "Total amount of created orders.",
+		Buckets: prometheus.ExponentialBuckets(5, 2, 12),
+	})
+
+	stockOutRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "stock_out_rejections_total",
+		Help: "Checkouts rejected because an item was out of stock.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		ordersCreated,
+		orderValue,
+		stockOutRejections,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveHTTPRequest records a served request. route is the route template,
+// e.g. /api/orders/:id, never the raw path.
+func ObserveHTTPRequest(method, route string, status int, latency time.Duration) {
+	method = methodLabel(method)
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(latency.Seconds())
+}
+
+// methodLabel keeps the standard methods and reports any other as OTHER, so
+// clients cannot add label values by sending made up methods.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// OrderCreated records a stored order.
+func OrderCreated(status string, totalAmount float64) {
+	ordersCreated.WithLabelValues(status).Inc()
+	orderValue.Observe(totalAmount)
+}
+
+// StockOutRejected records a checkout that failed for lack of stock.
+func StockOutRejected() {
+	stockOutRejections.Inc()
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	mongoConnsOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_open_conns",
+		Help: "Connections open in the MongoDB pools.",
+	})
+
+	mongoConnsInUse = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mongo_pool_in_use_conns",
+		Help: "Connections checked out of the MongoDB pools.",
+	})
+
+	mongoCheckoutDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mongo_pool_checkout_duration_seconds",
+		Help:    "Time spent waiting for a MongoDB connection.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 4, 8),
+	})
+
+	mongoCheckoutFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Failed checkouts of a MongoDB connection.",
+	})
+
+	mongoOnce sync.Once
+)
+
+// MongoPoolMonitor returns a pool monitor that exports the pool statistics of
+// the MongoDB client it is set on. The metrics only appear once it is used.
+func MongoPoolMonitor() *event.PoolMonitor {
+	mongoOnce.Do(func() {
+		registry.MustRegister(mongoConnsOpen, mongoConnsInUse, mongoCheckoutDuration, mongoCheckoutFailures)
+	})
+
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				mongoConnsOpen.Inc()
+			case event.ConnectionClosed:
+				mongoConnsOpen.Dec()
+			case event.GetSucceeded:
+				mongoConnsInUse.Inc()
+				mongoCheckoutDuration.Observe(e.Duration.Seconds())
+			case event.ConnectionReturned:
+				mongoConnsInUse.Dec()
+			case event.GetFailed:
+				mongoCheckoutFailures.Inc()
+				mongoCheckoutDuration.Observe(e.Duration.Seconds())
+			}
+		},
+	}
+}
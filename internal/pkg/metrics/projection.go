@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ProjectionLag is how far a projection trails the order events.
+type ProjectionLag struct {
+	Projection    string
+	EventsBehind  int64
+	SecondsBehind float64
+}
+
+// projectionLagCollector reads the projection checkpoints on every scrape.
+type projectionLagCollector struct {
+	lag     func(ctx context.Context) ([]ProjectionLag, error)
+	timeout time.Duration
+
+	events  *prometheus.Desc
+	seconds *prometheus.Desc
+}
+
+// RegisterProjectionLag exports the lag of every projection, read by lag
+// within timeout. A scrape during which lag fails leaves the gauges out
+// rather than failing the other metrics.
+func RegisterProjectionLag(timeout time.Duration, lag func(ctx context.Context) ([]ProjectionLag, error)) error {
+	return registry.Register(&projectionLagCollector{
+		lag:     lag,
+		timeout: timeout,
+		events: prometheus.NewDesc("projection_lag_events",
+			"Order events the projection has not applied yet.", []string{"projection"}, nil),
+		seconds: prometheus.NewDesc("projection_lag_seconds",
+			"Age of the oldest order event the projection has not applied yet.", []string{"projection"}, nil),
+	})
+}
+
+func (c *projectionLagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.events
+	ch <- c.seconds
+}
+
+func (c *projectionLagCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	lags, err := c.lag(ctx)
+	if err != nil {
+		logger.Warn("Failed to read projection lag", zap.Error(err))
+		return
+	}
+	for _, lag := range lags {
+		ch <- prometheus.MustNewConstMetric(c.events, prometheus.GaugeValue, float64(lag.EventsBehind), lag.Projection)
+		ch <- prometheus.MustNewConstMetric(c.seconds, prometheus.GaugeValue, lag.SecondsBehind, lag.Projection)
+	}
+}
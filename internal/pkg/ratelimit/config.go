@@ -0,0 +1,137 @@
+// Package ratelimit limits how often each client may call the API using
+// token buckets. Buckets live in a Store: in memory for a single instance or
+// in Redis when several instances share the limits.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Config sets the default limit of every client and overrides for single
+// routes. Routes without an override share one bucket per client. Address
+// limits every client address before authentication, so that requests with
+// missing or invalid credentials are limited too.
+type Config struct {
+	Enabled   bool        `json:"enabled"`
+	Store     string      `json:"store"`
+	KeyPrefix string      `json:"key_prefix"`
+	Address   Rule        `json:"address"`
+	Default   Rule        `json:"default"`
+	Routes    []RouteRule `json:"routes"`
+}
+
+// Rule refills RequestsPerMinute tokens a minute up to Burst. Burst defaults
+// to RequestsPerMinute; a zero RequestsPerMinute disables the limit.
+type Rule struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
+}
+
+// RouteRule overrides the default Rule of a route. Path is the route
+// template, e.g. /api/orders/:id/payments.
+type RouteRule struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Rule
+}
+
+// LoadConfig reads Config from a JSON file and validates it.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read rate limit config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal rate limit config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	switch c.Store {
+	case "":
+		c.Store = StoreMemory
+	case StoreMemory, StoreRedis:
+	default:
+		return fmt.Errorf("unknown rate limit store %q", c.Store)
+	}
+
+	if err := c.Address.validate(); err != nil {
+		return fmt.Errorf("address rate limit: %w", err)
+	}
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("default rate limit: %w", err)
+	}
+
+	seen := make(map[string]bool, len(c.Routes))
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		route.Method = strings.ToUpper(route.Method)
+		if route.Method == "" || route.Path == "" {
+			return fmt.Errorf("rate limit route %d needs a method and a path", i)
+		}
+		if seen[route.key()] {
+			return fmt.Errorf("duplicate rate limit for %s", route.key())
+		}
+		seen[route.key()] = true
+
+		if err := route.validate(); err != nil {
+			return fmt.Errorf("rate limit of %s: %w", route.key(), err)
+		}
+	}
+	return nil
+}
+
+func (r RouteRule) key() string {
+	return r.Method + " " + r.Path
+}
+
+func (r Rule) validate() error {
+	if r.RequestsPerMinute < 0 || r.Burst < 0 {
+		return fmt.Errorf("requests_per_minute and burst must not be negative")
+	}
+	return nil
+}
+
+func (r Rule) unlimited() bool {
+	return r.RequestsPerMinute == 0
+}
+
+func (r Rule) burst() int {
+	if r.Burst == 0 {
+		return r.RequestsPerMinute
+	}
+	return r.Burst
+}
+
+// rate is the refill rate in tokens per second.
+func (r Rule) rate() float64 {
+	return float64(r.RequestsPerMinute) / 60
+}
+
+// fillTime is how long an empty bucket takes to fill up. A full bucket is
+// the same as no bucket, so stores may forget buckets idle for this long.
+func (r Rule) fillTime() time.Duration {
+	return time.Duration(float64(r.burst()) / r.rate() * float64(time.Second))
+}
+
+// policy describes the rule in the RateLimit-Policy header format.
+func (r Rule) policy() string {
+	return fmt.Sprintf("%d;w=60;burst=%d", r.RequestsPerMinute, r.burst())
+}
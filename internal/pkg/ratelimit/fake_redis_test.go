@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fakeRedis is an in-process stand-in for Redis that runs the token bucket
+// script of RedisStore, so the store can be tested without a server. It
+// mimics the script cache: EVALSHA of a script that was never loaded or
+// evaluated fails with NOSCRIPT. Other scripts are rejected.
+type fakeRedis struct {
+	mu      sync.Mutex
+	scripts map[string]bool
+	hashes  map[string]fakeHash
+}
+
+type fakeHash struct {
+	tokens    string
+	updated   int64
+	expiresAt int64
+}
+
+type fakeRedisError string
+
+func (e fakeRedisError) Error() string { return string(e) }
+
+func (fakeRedisError) RedisError() {}
+
+var _ redis.Scripter = (*fakeRedis)(nil)
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		scripts: make(map[string]bool),
+		hashes:  make(map[string]fakeHash),
+	}
+}
+
+// Len returns the number of buckets that have not expired by now.
+func (f *fakeRedis) Len(now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, h := range f.hashes {
+		if h.expiresAt > now.UnixMicro() {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	sha := scriptSHA(script)
+	f.mu.Lock()
+	f.scripts[sha] = true
+	f.mu.Unlock()
+	return f.EvalSha(ctx, sha, keys, args...)
+}
+
+func (f *fakeRedis) EvalSha(_ context.Context, sha string, keys []string, args ...any) *redis.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.scripts[sha] {
+		return redis.NewCmdResult(nil, fakeRedisError("NOSCRIPT No matching script. Please use EVAL."))
+	}
+	if sha != scriptSHA(tokenBucketScript) {
+		return redis.NewCmdResult(nil, fakeRedisError("ERR script not supported by fakeRedis"))
+	}
+	if len(keys) != 1 || len(args) != 3 {
+		return redis.NewCmdResult(nil, fakeRedisError("ERR wrong number of keys or arguments"))
+	}
+	return redis.NewCmdResult(f.tokenBucket(keys[0], args))
+}
+
+func (f *fakeRedis) EvalRO(_ context.Context, _ string, _ []string, _ ...any) *redis.Cmd {
+	return redis.NewCmdResult(nil, fakeRedisError("ERR read-only scripts are not supported by fakeRedis"))
+}
+
+func (f *fakeRedis) EvalShaRO(_ context.Context, _ string, _ []string, _ ...any) *redis.Cmd {
+	return redis.NewCmdResult(nil, fakeRedisError("ERR read-only scripts are not supported by fakeRedis"))
+}
+
+func (f *fakeRedis) ScriptExists(_ context.Context, hashes ...string) *redis.BoolSliceCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	exists := make([]bool, len(hashes))
+	for i, sha := range hashes {
+		exists[i] = f.scripts[sha]
+	}
+	return redis.NewBoolSliceResult(exists, nil)
+}
+
+func (f *fakeRedis) ScriptLoad(_ context.Context, script string) *redis.StringCmd {
+	sha := scriptSHA(script)
+	f.mu.Lock()
+	f.scripts[sha] = true
+	f.mu.Unlock()
+	return redis.NewStringResult(sha, nil)
+}
+
+// tokenBucket does what tokenBucketScript does. Arguments arrive as strings
+// like they would in Redis.
+func (f *fakeRedis) tokenBucket(key string, args []any) (any, error) {
+	var values [3]float64
+	for i, arg := range args {
+		v, err := strconv.ParseFloat(fmt.Sprint(arg), 64)
+		if err != nil {
+			return nil, fakeRedisError("ERR value is not a number")
+		}
+		values[i] = v
+	}
+	rate, burst, now := values[0], values[1], int64(values[2])
+
+	h, found := f.hashes[key]
+	tokens, updated := burst, now
+	if found && h.expiresAt > now {
+		tokens, _ = strconv.ParseFloat(h.tokens, 64)
+		updated = h.updated
+	}
+
+	if now > updated {
+		tokens = math.Min(burst, tokens+float64(now-updated)/1e6*rate)
+		updated = now
+	}
+
+	var allowed int64
+	if tokens >= 1 {
+		tokens--
+		allowed = 1
+	}
+
+	// Lua's tostring prints 14 significant digits
+	left := strconv.FormatFloat(tokens, 'g', 14, 64)
+	f.hashes[key] = fakeHash{
+		tokens:    left,
+		updated:   updated,
+		expiresAt: now + int64(math.Ceil(burst/rate*1000))*1000,
+	}
+	return []any{allowed, left}, nil
+}
+
+func scriptSHA(script string) string {
+	sum := sha1.Sum([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Store keeps the token buckets. Take refills the bucket at key for the time
+// passed since its last use, then takes one token if there is one.
+type Store interface {
+	Take(ctx context.Context, key string, rule Rule, now time.Time) (Decision, error)
+}
+
+// Decision is the outcome of one request against its bucket. Limited is
+// false when no limit applies to the request; the other fields are then
+// zero.
+type Decision struct {
+	Limited    bool
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+	Policy     string
+}
+
+// Limiter picks the rule of a route and takes a token from the bucket of
+// the calling client.
+type Limiter struct {
+	store   Store
+	prefix  string
+	enabled bool
+	address Rule
+	dflt    Rule
+	routes  map[string]Rule
+	now     func() time.Time
+}
+
+func NewLimiter(cfg Config, store Store) *Limiter {
+	routes := make(map[string]Rule, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route.key()] = route.Rule
+	}
+
+	return &Limiter{
+		store:   store,
+		prefix:  cfg.KeyPrefix,
+		enabled: cfg.Enabled,
+		address: cfg.Address,
+		dflt:    cfg.Default,
+		routes:  routes,
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token for client, e.g. api_key:3, user:42 or ip:10.0.0.1,
+// calling the route template of method.
+func (l *Limiter) Allow(ctx context.Context, method, route, client string) (Decision, error) {
+	if !l.enabled {
+		return Decision{}, nil
+	}
+
+	scope := method + " " + route
+	rule, found := l.routes[scope]
+	if !found {
+		scope, rule = "default", l.dflt
+	}
+	if rule.unlimited() {
+		return Decision{}, nil
+	}
+
+	return l.store.Take(ctx, l.prefix+scope+"|"+client, rule, l.now())
+}
+
+// AllowAddress takes a token from the bucket of a client address, which
+// every request spends from whether or not it authenticates.
+func (l *Limiter) AllowAddress(ctx context.Context, ip string) (Decision, error) {
+	if !l.enabled || l.address.unlimited() {
+		return Decision{}, nil
+	}
+
+	return l.store.Take(ctx, l.prefix+"address|ip:"+ip, l.address, l.now())
+}
+
+// bucket is the state of one token bucket.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newBucket(rule Rule, now time.Time) bucket {
+	return bucket{tokens: float64(rule.burst()), updated: now}
+}
+
+// take refills b up to now and takes a token if there is one.
+func (b *bucket) take(rule Rule, now time.Time) bool {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(rule.burst()), b.tokens+elapsed.Seconds()*rule.rate())
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// decide describes a bucket holding tokens after a request was allowed or
+// not.
+func decide(rule Rule, allowed bool, tokens float64) Decision {
+	d := Decision{
+		Limited:   true,
+		Allowed:   allowed,
+		Limit:     rule.burst(),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(rule.burst()) - tokens) / rule.rate()),
+		Policy:    rule.policy(),
+	}
+	if !allowed {
+		d.RetryAfter = seconds((1 - tokens) / rule.rate())
+	}
+	return d
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
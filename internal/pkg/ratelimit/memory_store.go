@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many Take calls pass between removals of idle buckets.
+const sweepEvery = 1024
+
+// MemoryStore keeps buckets in process. Each instance limits on its own, so
+// with N instances behind a load balancer a client gets up to N times the
+// configured rate.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+}
+
+type memoryBucket struct {
+	bucket
+	idleAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rule Rule, now time.Time) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	b, found := s.buckets[key]
+	if !found {
+		b = &memoryBucket{bucket: newBucket(rule, now)}
+		s.buckets[key] = b
+	}
+
+	allowed := b.take(rule, now)
+	b.idleAt = now.Add(rule.fillTime())
+	return decide(rule, allowed, b.tokens), nil
+}
+
+// sweep forgets buckets that have refilled completely.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.idleAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// tokenBucketScript refills and takes from the bucket hash at KEYS[1] in one
+// atomic step. ARGV holds the refill rate in tokens per second, the burst
+// and the current time in microseconds; it returns whether a token was
+// taken and the tokens left. The bucket expires once it would be full again.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+  tokens = burst
+  updated = now
+end
+
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) / 1e6 * rate)
+  updated = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', updated)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000))
+return {allowed, tostring(tokens)}
+`
+
+var tokenBucket = redis.NewScript(tokenBucketScript)
+
+// RedisStore keeps buckets in Redis so that all instances share the limits.
+// It accepts any redis.Scripter, e.g. a client or a cluster client.
+// The time comes from the instances, so their clocks should be in sync.
+type RedisStore struct {
+	client redis.Scripter
+}
+
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, rule Rule, now time.Time) (Decision, error) {
+	res, err := tokenBucket.Run(ctx, s.client, []string{key}, rule.rate(), rule.burst(), now.UnixMicro()).Slice()
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	allowed, tokens, err := parseTokenBucketReply(res)
+	if err != nil {
+		return Decision{}, err
+	}
+	return decide(rule, allowed, tokens), nil
+}
+
+func parseTokenBucketReply(res []any) (bool, float64, error) {
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+	allowed, ok := res[0].(int64)
+	if !ok {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+	tokens, ok := res[1].(string)
+	if !ok {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+
+	left, err := strconv.ParseFloat(tokens, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+	return allowed == 1, left, nil
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var testRule = Rule{RequestsPerMinute: 60, Burst: 3}
+
+// stores returns a fresh MemoryStore and a RedisStore on a fakeRedis, which
+// must decide every request alike.
+func stores() map[string]Store {
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  NewRedisStore(newFakeRedis()),
+	}
+}
+
+func take(t *testing.T, store Store, key string, now time.Time) Decision {
+	t.Helper()
+
+	d, err := store.Take(context.Background(), key, testRule, now)
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	return d
+}
+
+func TestStoresAllowBurstThenLimit(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+
+	for name, store := range stores() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < testRule.Burst; i++ {
+				d := take(t, store, "client", start)
+				if !d.Allowed || d.Remaining != testRule.Burst-1-i {
+					t.Fatalf("request %d: got allowed=%v remaining=%d", i, d.Allowed, d.Remaining)
+				}
+			}
+
+			d := take(t, store, "client", start)
+			if d.Allowed {
+				t.Fatal("request beyond the burst was allowed")
+			}
+			if d.RetryAfter != time.Second {
+				t.Errorf("RetryAfter = %v, want 1s", d.RetryAfter)
+			}
+			if d.Reset != 3*time.Second {
+				t.Errorf("Reset = %v, want 3s", d.Reset)
+			}
+
+			if other := take(t, store, "other", start); !other.Allowed {
+				t.Error("another client was limited")
+			}
+		})
+	}
+}
+
+func TestStoresRefill(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+
+	for name, store := range stores() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < testRule.Burst; i++ {
+				take(t, store, "client", start)
+			}
+
+			// One token a second; half a token is not enough
+			d := take(t, store, "client", start.Add(500*time.Millisecond))
+			if d.Allowed {
+				t.Fatal("allowed before a token was refilled")
+			}
+			if d.RetryAfter != 500*time.Millisecond {
+				t.Errorf("RetryAfter = %v, want 500ms", d.RetryAfter)
+			}
+
+			d = take(t, store, "client", start.Add(time.Second))
+			if !d.Allowed || d.Remaining != 0 {
+				t.Fatalf("after 1s: got allowed=%v remaining=%d", d.Allowed, d.Remaining)
+			}
+
+			// The bucket never holds more than the burst
+			d = take(t, store, "client", start.Add(time.Hour))
+			if !d.Allowed || d.Remaining != testRule.Burst-1 {
+				t.Fatalf("after 1h: got allowed=%v remaining=%d", d.Allowed, d.Remaining)
+			}
+		})
+	}
+}
+
+func TestStoresAgree(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	offsets := []time.Duration{
+		0, 0, 100 * time.Millisecond, 200 * time.Millisecond, 200 * time.Millisecond,
+		1300 * time.Millisecond, 1300 * time.Millisecond, 1300 * time.Millisecond,
+		2900 * time.Millisecond, 7 * time.Second, 7 * time.Second,
+	}
+
+	memory := NewMemoryStore()
+	redis := NewRedisStore(newFakeRedis())
+	for i, offset := range offsets {
+		now := start.Add(offset)
+		want := take(t, memory, "client", now)
+		got := take(t, redis, "client", now)
+		if !sameDecision(got, want) {
+			t.Errorf("request %d at +%v: redis decided %+v, memory %+v", i, offset, got, want)
+		}
+	}
+}
+
+// sameDecision compares durations to the millisecond; Redis passes the
+// tokens left as a string of 14 significant digits.
+func sameDecision(a, b Decision) bool {
+	near := func(x, y time.Duration) bool {
+		return (x - y).Abs() < time.Millisecond
+	}
+	return near(a.Reset, b.Reset) && near(a.RetryAfter, b.RetryAfter) &&
+		a.Limited == b.Limited && a.Allowed == b.Allowed && a.Limit == b.Limit &&
+		a.Remaining == b.Remaining && a.Policy == b.Policy
+}
+
+func TestStoresForgetFullBuckets(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	full := start.Add(testRule.fillTime())
+
+	memory := NewMemoryStore()
+	take(t, memory, "client", start)
+	memory.sweep(full.Add(-time.Millisecond))
+	if len(memory.buckets) != 1 {
+		t.Fatal("memory store forgot a bucket before it was full")
+	}
+	memory.sweep(full)
+	if len(memory.buckets) != 0 {
+		t.Error("memory store kept a full bucket")
+	}
+
+	fake := newFakeRedis()
+	take(t, NewRedisStore(fake), "client", start)
+	if fake.Len(full.Add(-time.Millisecond)) != 1 {
+		t.Fatal("redis store expired a bucket before it was full")
+	}
+	if fake.Len(full) != 0 {
+		t.Error("redis store kept a full bucket")
+	}
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where spans are exported to. Without Enabled no spans are
+// recorded, but incoming trace context is still propagated to the logs.
+type Config struct {
+	Enabled     bool   `json:"enabled"`
+	ServiceName string `json:"service_name"`
+	Exporter    string `json:"exporter"`
+	// SampleRatio is the share of new traces recorded; requests carrying a
+	// sampled trace context are always recorded.
+	SampleRatio float64      `json:"sample_ratio"`
+	Stdout      StdoutConfig `json:"stdout"`
+	OTLP        OTLPConfig   `json:"otlp"`
+}
+
+// StdoutConfig writes spans as JSON lines to Path, or to stdout when empty.
+// The file is rotated like the log files; without Rotation it is rotated at
+// 100 MB and the newest 5 rotated files are kept gzipped.
+type StdoutConfig struct {
+	Path     string                 `json:"path"`
+	Pretty   bool                   `json:"pretty"`
+	Rotation *logger.RotationConfig `json:"rotation"`
+}
+
+// OTLPConfig sends spans to an OpenTelemetry collector over OTLP/HTTP. The
+// OTEL_EXPORTER_OTLP_* environment variables override these settings.
+type OTLPConfig struct {
+	Endpoint string            `json:"endpoint"`
+	Insecure bool              `json:"insecure"`
+	Headers  map[string]string `json:"headers"`
+}
+
+// LoadConfig reads Config from a JSON file and validates it.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read tracing config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal tracing config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch c.Exporter {
+	case ExporterStdout, ExporterOTLP:
+	default:
+		return fmt.Errorf("unknown tracing exporter %q", c.Exporter)
+	}
+
+	if c.ServiceName == "" {
+		return fmt.Errorf("tracing service_name cannot be empty")
+	}
+
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample_ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Package tracing records OpenTelemetry spans of HTTP requests, use cases and
+// database queries, and exports them as configured.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Sinet2000/Martix-Orders-Go"
+
+// Tracer returns the tracer of the service. It follows the provider
+// installed by Setup, also when obtained before.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Setup installs the W3C trace context propagator and, when enabled, a tracer
+// provider exporting as cfg says. shutdown flushes the spans not yet
+// exported.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.OTLP.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLP.Endpoint))
+		}
+		if cfg.OTLP.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.OTLP.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.OTLP.Headers))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		return exporter, noClose, nil
+
+	default:
+		var out io.Writer = os.Stdout
+		closeOutput := noClose
+		if cfg.Stdout.Path != "" {
+			rotation := logger.RotationConfig{MaxBackups: 5, Compress: true}
+			if cfg.Stdout.Rotation != nil {
+				rotation = *cfg.Stdout.Rotation
+			}
+			file, err := logger.OpenRotating(cfg.Stdout.Path, rotation)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to open trace output: %w", err)
+			}
+			out, closeOutput = file, file.Close
+		}
+
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(out)}
+		if cfg.Stdout.Pretty {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+		exporter, err := stdouttrace.New(opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout trace exporter: %w", err)
+		}
+		return exporter, closeOutput, nil
+	}
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+// Finish it with End.
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name)
+}
+
+// End records *errp, if not nil, on span and ends it. Deferred with a named
+// error result, it sees the error that is returned:
+//
+//	ctx, span := tracing.Start(ctx, "CreateOrderUseCase.Execute")
+//	defer tracing.End(span, &err)
+func End(span trace.Span, errp *error) {
+	if errp != nil && *errp != nil {
+		span.RecordError(*errp)
+		span.SetStatus(codes.Error, (*errp).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,111 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys look like mok_<prefix>_<secret>. The prefix is stored in clear to
+// find the key; the whole key is only stored as a SHA-256 hash.
+const (
+	keyScheme    = "mok"
+	prefixBytes  = 4
+	secretBytes  = 32
+	prefixLength = len(keyScheme) + 1 + 2*prefixBytes
+)
+
+// AuthenticateAPIKeyUseCase turns the API key of a request into the
+// identity of the calling service.
+type AuthenticateAPIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAuthenticateAPIKeyUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+) *AuthenticateAPIKeyUseCase {
+	return &AuthenticateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Authenticate returns the identity of an active key. Unknown, wrong,
+// expired and revoked keys all fail with entity.ErrInvalidAPIKey so callers
+// cannot probe which keys exist.
+func (uc *AuthenticateAPIKeyUseCase) Authenticate(ctx context.Context, raw string) (_ *identity.Identity, err error) {
+	ctx, span := tracing.Start(ctx, "AuthenticateAPIKeyUseCase.Authenticate")
+	defer tracing.End(span, &err)
+
+	prefix, ok := splitKey(raw)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed key", entity.ErrInvalidAPIKey)
+	}
+
+	key, err := uc.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, entity.ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("%w: unknown key", entity.ErrInvalidAPIKey)
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashKey(raw)), []byte(key.KeyHash)) != 1 {
+		return nil, fmt.Errorf("%w: hash mismatch", entity.ErrInvalidAPIKey)
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, fmt.Errorf("%w: key %d is expired or revoked", entity.ErrInvalidAPIKey, key.ID)
+	}
+
+	// Failing to record the use must not fail the request
+	if err := uc.apiKeyRepo.TouchAPIKey(ctx, key.ID, now); err != nil {
+		logger.FromContext(ctx).Warn("Failed to record API key use", zap.Error(err), zap.Int64("api_key_id", key.ID))
+	}
+
+	return &identity.Identity{
+		Subject: "api_key:" + strconv.FormatInt(key.ID, 10),
+		Scopes:  key.Scopes,
+	}, nil
+}
+
+// generate fills the prefix and hash of key and returns the plaintext.
+func generate(key *entity.APIKey) (string, error) {
+	buf := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key.Prefix = keyScheme + "_" + hex.EncodeToString(buf[:prefixBytes])
+	plaintext := key.Prefix + "_" + hex.EncodeToString(buf[prefixBytes:])
+	key.KeyHash = hashKey(plaintext)
+	return plaintext, nil
+}
+
+func splitKey(raw string) (string, bool) {
+	if len(raw) != prefixLength+1+2*secretBytes || !strings.HasPrefix(raw, keyScheme+"_") {
+		return "", false
+	}
+	if raw[prefixLength] != '_' {
+		return "", false
+	}
+	return raw[:prefixLength], true
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+// MaxRotationGrace bounds how long a rotated key keeps working next to its
+// replacement.
+const MaxRotationGrace = 7 * 24 * time.Hour
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey carries the plaintext key. It is only available when the key
+// is created or rotated; afterwards only its hash is stored.
+type IssuedAPIKey struct {
+	APIKey *entity.APIKey `json:"api_key"`
+	Key    string         `json:"key"`
+}
+
+// ManageAPIKeysUseCase implements the admin operations on API keys.
+type ManageAPIKeysUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewManageAPIKeysUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+) *ManageAPIKeysUseCase {
+	return &ManageAPIKeysUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (uc *ManageAPIKeysUseCase) Create(ctx context.Context, input CreateAPIKeyInput) (_ *IssuedAPIKey, err error) {
+	ctx, span := tracing.Start(ctx, "ManageAPIKeysUseCase.Create")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.APIKeysManage); err != nil {
+		return nil, err
+	}
+
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &entity.APIKey{
+		Name:      input.Name,
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := key.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := generate(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.apiKeyRepo.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("API key created",
+		zap.Int64("api_key_id", key.ID),
+		zap.String("name", key.Name),
+		zap.String("prefix", key.Prefix),
+		zap.Strings("scopes", key.Scopes),
+	)
+
+	return &IssuedAPIKey{APIKey: key, Key: plaintext}, nil
+}
+
+func (uc *ManageAPIKeysUseCase) List(ctx context.Context) (_ []entity.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "ManageAPIKeysUseCase.List")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.APIKeysManage); err != nil {
+		return nil, err
+	}
+
+	return uc.apiKeyRepo.ListAPIKeys(ctx)
+}
+
+// Rotate issues a replacement with the same name, scopes and expiry. The old
+// key keeps working for grace so callers can switch over without downtime.
+func (uc *ManageAPIKeysUseCase) Rotate(ctx context.Context, keyID int64, grace time.Duration) (_ *IssuedAPIKey, err error) {
+	ctx, span := tracing.Start(ctx, "ManageAPIKeysUseCase.Rotate")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.APIKeysManage); err != nil {
+		return nil, err
+	}
+	if grace < 0 || grace > MaxRotationGrace {
+		return nil, fmt.Errorf("%w: grace period must be between 0 and %s", entity.ErrInvalidAPIKey, MaxRotationGrace)
+	}
+
+	old, err := uc.apiKeyRepo.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !old.Active(now) {
+		return nil, fmt.Errorf("%w: %d", entity.ErrAPIKeyRevoked, keyID)
+	}
+
+	replacement := &entity.APIKey{
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		ExpiresAt: old.ExpiresAt,
+	}
+	plaintext, err := generate(replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.apiKeyRepo.RotateAPIKey(ctx, keyID, replacement, now.Add(grace)); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("API key rotated",
+		zap.Int64("api_key_id", keyID),
+		zap.Int64("replacement_id", replacement.ID),
+		zap.Duration("grace", grace),
+	)
+
+	return &IssuedAPIKey{APIKey: replacement, Key: plaintext}, nil
+}
+
+func (uc *ManageAPIKeysUseCase) Revoke(ctx context.Context, keyID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "ManageAPIKeysUseCase.Revoke")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.APIKeysManage); err != nil {
+		return err
+	}
+
+	if err := uc.apiKeyRepo.RevokeAPIKey(ctx, keyID, time.Now()); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("API key revoked", zap.Int64("api_key_id", keyID))
+	return nil
+}
+
+// normalizeScopes rejects scopes that are not permissions, so a typo cannot
+// silently issue a key that is denied everything.
+func normalizeScopes(scopes []string) ([]string, error) {
+	normalized := slices.Clone(scopes)
+	for _, scope := range normalized {
+		if !slices.Contains(authz.Permissions, authz.Permission(scope)) {
+			return nil, fmt.Errorf("%w: unknown scope %q", entity.ErrInvalidAPIKey, scope)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
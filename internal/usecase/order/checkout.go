@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/metrics"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/usecase/saga"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CheckoutSaga = "checkout"
+
+var ErrMissingPaymentMethod = errors.New("payment method is required")
+
+type CheckoutInput struct {
+	OrderID       int64  `json:"-"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+type CheckoutOutput struct {
+	SagaID      int64              `json:"saga_id"`
+	SagaStatus  entity.SagaStatus  `json:"saga_status"`
+	OrderID     int64              `json:"order_id"`
+	OrderStatus entity.OrderStatus `json:"order_status"`
+	PaymentID   int64              `json:"payment_id"`
+}
+
+// checkoutData is the persisted state of a checkout saga.
+type checkoutData struct {
+	OrderID         int64   `json:"order_id"`
+	PaymentMethod   string  `json:"payment_method"`
+	Amount          float64 `json:"amount"`
+	PaymentID       int64   `json:"payment_id"`
+	AuthorizationID string  `json:"authorization_id"`
+	CaptureID       string  `json:"capture_id"`
+}
+
+// CheckoutUseCase takes a pending order through reserve stock, authorize
+// payment and confirm order as a saga. A failed step releases the stock and
+// voids the payment again.
+type CheckoutUseCase struct {
+	orderRepo     repository.OrderRepository
+	paymentRepo   repository.PaymentRepository
+	inventoryRepo repository.InventoryRepository
+	gateway       service.PaymentGateway
+	orchestrator  *saga.Orchestrator
+}
+
+func NewCheckoutUseCase(
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.PaymentRepository,
+	inventoryRepo repository.InventoryRepository,
+	gateway service.PaymentGateway,
+	orchestrator *saga.Orchestrator,
+	stepTimeout time.Duration,
+) *CheckoutUseCase {
+	uc := &CheckoutUseCase{
+		orderRepo:     orderRepo,
+		paymentRepo:   paymentRepo,
+		inventoryRepo: inventoryRepo,
+		gateway:       gateway,
+		orchestrator:  orchestrator,
+	}
+
+	saga.Register(orchestrator, saga.Definition[checkoutData]{
+		Name: CheckoutSaga,
+		Steps: []saga.Step[checkoutData]{
+			{
+				Name:       "reserve_stock",
+				Timeout:    stepTimeout,
+				Action:     uc.reserveStock,
+				Compensate: uc.releaseStock,
+			},
+			{
+				Name:       "authorize_payment",
+				Timeout:    stepTimeout,
+				Action:     uc.authorizePayment,
+				Compensate: uc.voidPayment,
+			},
+			{
+				Name:       "confirm_order",
+				Timeout:    stepTimeout,
+				Action:     uc.confirmOrder,
+				Compensate: uc.refundPayment,
+			},
+		},
+	})
+
+	return uc
+}
+
+func (uc *CheckoutUseCase) Execute(ctx context.Context, input CheckoutInput) (_ *CheckoutOutput, err error) {
+	ctx, span := tracing.Start(ctx, "CheckoutUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersPay); err != nil {
+		return nil, err
+	}
+
+	paymentMethod := strings.TrimSpace(input.PaymentMethod)
+	if paymentMethod == "" {
+		return nil, ErrMissingPaymentMethod
+	}
+
+	order, err := uc.orderRepo.GetOrderByID(ctx, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !authz.CanAccess(ctx, order.UserID, authz.OrdersRead) {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, input.OrderID)
+	}
+	if order.Status != entity.OrderStatusPending {
+		return nil, entity.ErrOrderNotPayable
+	}
+
+	data := &checkoutData{
+		OrderID:       order.ID,
+		PaymentMethod: paymentMethod,
+		Amount:        order.TotalAmount,
+	}
+	state, err := saga.Start(ctx, uc.orchestrator, CheckoutSaga, strconv.FormatInt(order.ID, 10), data)
+	if state == nil {
+		return nil, err
+	}
+
+	output := &CheckoutOutput{
+		SagaID:      state.ID,
+		SagaStatus:  state.Status,
+		OrderID:     order.ID,
+		OrderStatus: order.Status,
+		PaymentID:   data.PaymentID,
+	}
+	if state.Status == entity.SagaStatusCompleted {
+		output.OrderStatus = entity.OrderStatusPaid
+	}
+	return output, err
+}
+
+func (uc *CheckoutUseCase) reserveStock(ctx context.Context, data *checkoutData) error {
+	err := uc.inventoryRepo.ReserveStock(ctx, data.OrderID)
+	if errors.Is(err, entity.ErrInsufficientStock) {
+		metrics.StockOutRejected()
+	}
+	return err
+}
+
+func (uc *CheckoutUseCase) releaseStock(ctx context.Context, data *checkoutData) error {
+	return uc.inventoryRepo.ReleaseStock(ctx, data.OrderID)
+}
+
+func (uc *CheckoutUseCase) authorizePayment(ctx context.Context, data *checkoutData) error {
+	payment, err := uc.currentPayment(ctx, data)
+	if err != nil {
+		return err
+	}
+	if payment.Status != entity.PaymentStatusPending {
+		// Authorized before a crash
+		return nil
+	}
+
+	authorizationID, err := uc.gateway.Authorize(ctx, service.AuthorizeRequest{
+		OrderID:        data.OrderID,
+		Amount:         data.Amount,
+		PaymentMethod:  data.PaymentMethod,
+		IdempotencyKey: fmt.Sprintf("order-%d-payment-%d", data.OrderID, payment.ID),
+	})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, entity.ErrPaymentTimeout) {
+			err = fmt.Errorf("%w: %v", entity.ErrPaymentTimeout, err)
+		}
+		payment.Status = entity.PaymentStatusFailed
+		if errors.Is(err, entity.ErrPaymentDeclined) {
+			payment.Status = entity.PaymentStatusDeclined
+		}
+		payment.FailureReason = err.Error()
+		payment.UpdatedAt = time.Now()
+		if updateErr := uc.paymentRepo.UpdatePayment(context.WithoutCancel(ctx), payment); updateErr != nil {
+			return errors.Join(err, updateErr)
+		}
+		return err
+	}
+
+	data.AuthorizationID = authorizationID
+	payment.AuthorizationID = authorizationID
+	payment.Status = entity.PaymentStatusAuthorized
+	payment.UpdatedAt = time.Now()
+	return uc.paymentRepo.UpdatePayment(ctx, payment)
+}
+
+// voidPayment releases the authorization. A payment that never got an
+// answer from the gateway is marked failed, and one the gateway would not
+// void void_failed, so it no longer blocks a retry.
+func (uc *CheckoutUseCase) voidPayment(ctx context.Context, data *checkoutData) error {
+	if data.PaymentID == 0 {
+		return nil
+	}
+	payment, err := uc.findPayment(ctx, data.OrderID, data.PaymentID)
+	if err != nil {
+		return err
+	}
+	switch payment.Status {
+	case entity.PaymentStatusPending:
+		payment.Status = entity.PaymentStatusFailed
+		payment.FailureReason = "checkout aborted before authorization"
+		payment.UpdatedAt = time.Now()
+		return uc.paymentRepo.UpdatePayment(ctx, payment)
+	case entity.PaymentStatusAuthorized:
+	default:
+		return nil
+	}
+
+	if err := uc.gateway.Void(ctx, payment.AuthorizationID); err != nil {
+		// Keep the payment from blocking a new checkout of the order; the
+		// failed saga flags the authorization for a manual void
+		payment.Status = entity.PaymentStatusVoidFailed
+		payment.FailureReason = fmt.Sprintf("void failed: %v", err)
+		payment.UpdatedAt = time.Now()
+		if updateErr := uc.paymentRepo.UpdatePayment(context.WithoutCancel(ctx), payment); updateErr != nil {
+			return errors.Join(err, updateErr)
+		}
+		return err
+	}
+
+	payment.Status = entity.PaymentStatusVoided
+	payment.UpdatedAt = time.Now()
+	return uc.paymentRepo.UpdatePayment(ctx, payment)
+}
+
+func (uc *CheckoutUseCase) confirmOrder(ctx context.Context, data *checkoutData) error {
+	payment, err := uc.findPayment(ctx, data.OrderID, data.PaymentID)
+	if err != nil {
+		return err
+	}
+	if payment.Status == entity.PaymentStatusCaptured {
+		// Confirmed before a crash
+		return nil
+	}
+
+	captureID, err := uc.gateway.Capture(ctx, payment.AuthorizationID, data.Amount)
+	if err != nil {
+		return err
+	}
+	data.CaptureID = captureID
+
+	payment.CaptureID = captureID
+	payment.Status = entity.PaymentStatusCaptured
+	payment.UpdatedAt = time.Now()
+	return uc.paymentRepo.CompletePayment(ctx, payment)
+}
+
+// refundPayment gives back money captured by a confirmation that failed
+// afterwards, and voids the authorization when nothing was captured.
+func (uc *CheckoutUseCase) refundPayment(ctx context.Context, data *checkoutData) error {
+	payment, err := uc.findPayment(ctx, data.OrderID, data.PaymentID)
+	if err != nil {
+		return err
+	}
+	if data.CaptureID == "" || payment.Status == entity.PaymentStatusRefunded {
+		// The authorize step's compensation voids the authorization
+		return nil
+	}
+
+	if _, err := uc.gateway.Refund(ctx, data.CaptureID, data.Amount); err != nil {
+		return err
+	}
+
+	payment.CaptureID = data.CaptureID
+	payment.Status = entity.PaymentStatusRefunded
+	payment.UpdatedAt = time.Now()
+	return uc.paymentRepo.UpdatePayment(ctx, payment)
+}
+
+// currentPayment returns the payment of this checkout, creating it on the
+// first run. A payment created just before a crash is picked up again.
+func (uc *CheckoutUseCase) currentPayment(ctx context.Context, data *checkoutData) (*entity.Payment, error) {
+	if data.PaymentID != 0 {
+		return uc.findPayment(ctx, data.OrderID, data.PaymentID)
+	}
+
+	payments, err := uc.paymentRepo.ListPayments(ctx, data.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		if payments[i].Status == entity.PaymentStatusPending && payments[i].PaymentMethod == data.PaymentMethod {
+			data.PaymentID = payments[i].ID
+			return &payments[i], nil
+		}
+	}
+
+	now := time.Now()
+	payment := &entity.Payment{
+		OrderID:       data.OrderID,
+		Amount:        data.Amount,
+		Provider:      uc.gateway.Name(),
+		PaymentMethod: data.PaymentMethod,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := uc.paymentRepo.CreatePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+	data.PaymentID = payment.ID
+	return payment, nil
+}
+
+func (uc *CheckoutUseCase) findPayment(ctx context.Context, orderID, paymentID int64) (*entity.Payment, error) {
+	payments, err := uc.paymentRepo.ListPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		if payments[i].ID == paymentID {
+			return &payments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", entity.ErrPaymentNotFound, paymentID)
+}
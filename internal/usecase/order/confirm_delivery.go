@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ConfirmDeliveryInput struct {
+	OrderID     int64      `json:"-"`
+	ShipmentID  int64      `json:"-"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+}
+
+type ConfirmDeliveryUseCase struct {
+	shipmentRepo repository.ShipmentRepository
+}
+
+func NewConfirmDeliveryUseCase(
+	shipmentRepo repository.ShipmentRepository,
+) *ConfirmDeliveryUseCase {
+	return &ConfirmDeliveryUseCase{
+		shipmentRepo: shipmentRepo,
+	}
+}
+
+func (uc *ConfirmDeliveryUseCase) Execute(ctx context.Context, input ConfirmDeliveryInput) (_ *ShipmentOutput, err error) {
+	ctx, span := tracing.Start(ctx, "ConfirmDeliveryUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ShipmentsWrite); err != nil {
+		return nil, err
+	}
+
+	deliveredAt := time.Now()
+	if input.DeliveredAt != nil {
+		deliveredAt = *input.DeliveredAt
+	}
+
+	shipment, status, err := uc.shipmentRepo.MarkShipmentDelivered(ctx, input.OrderID, input.ShipmentID, deliveredAt)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to confirm delivery",
+			zap.Error(err),
+			zap.Int64("order_id", input.OrderID),
+			zap.Int64("shipment_id", input.ShipmentID),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Shipment delivered",
+		zap.Int64("order_id", input.OrderID),
+		zap.Int64("shipment_id", input.ShipmentID),
+		zap.String("order_status", string(status)),
+	)
+
+	return &ShipmentOutput{
+		Shipment:    shipment,
+		OrderStatus: status,
+	}, nil
+}
@@ -3,9 +3,15 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
 	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/metrics"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
 	"go.uber.org/zap"
 	"time"
 )
@@ -13,8 +19,9 @@ import (
 var (
 	ErrEmptyOrder        = errors.New("order must contain at least one item")
 	ErrInvalidQuantity   = errors.New("item quantity must be greater than zero")
-	ErrInsufficientStock = errors.New("insufficient stock for one or more items")
+	ErrInsufficientStock = entity.ErrInsufficientStock
 	ErrProductNotFound   = errors.New("one or more products not found")
+	ErrMissingAddress    = errors.New("shipping address is required")
 )
 
 //type CreateOrderInput struct {
@@ -27,45 +34,85 @@ var (
 //}
 
 type CreateOrderInput struct {
-	UserID int64 `json:"user_id"`
+	// UserID is the authenticated caller; Execute sets it from the context
+	// and ignores any user_id in the request body.
+	UserID int64 `json:"-"`
 	Items  []struct {
 		ProductID  int64   `json:"product_id"`
 		Quantity   int     `json:"quantity"`
 		UnitPrice  float64 `json:"unit_price"`
 		TotalPrice float64 `json:"total_price"`
 	} `json:"items"`
-	ShippingAddr string `json:"shipping_addr"`
+	ShippingAddress *entity.Address `json:"shipping_address"`
+	BillingAddress  *entity.Address `json:"billing_address"`
+	// Deprecated: use ShippingAddress. Free text addresses are still accepted
+	// and parsed best-effort while clients migrate.
+	ShippingAddr   string `json:"shipping_addr"`
+	Notes          string `json:"notes"`
+	CouponCode     string `json:"coupon_code"`
+	ShippingMethod string `json:"shipping_method"`
 }
 
 type CreateOrderUseCase struct {
-	orderRepo *repository.OrderRepository
-	// productRepo *ProductRepository
+	orderRepo        repository.OrderRepository
+	productRepo      repository.ProductRepository
+	couponRepo       repository.CouponRepository
+	taxCalculator    service.TaxCalculator
+	shippingProvider service.ShippingRateProvider
 }
 
 func NewCreateOrderUseCase(
-	orderRepo *repository.OrderRepository,
+	orderRepo repository.OrderRepository,
+	productRepo repository.ProductRepository,
+	couponRepo repository.CouponRepository,
+	taxCalculator service.TaxCalculator,
+	shippingProvider service.ShippingRateProvider,
 ) *CreateOrderUseCase {
 	return &CreateOrderUseCase{
-		orderRepo: orderRepo,
+		orderRepo:        orderRepo,
+		productRepo:      productRepo,
+		couponRepo:       couponRepo,
+		taxCalculator:    taxCalculator,
+		shippingProvider: shippingProvider,
 	}
 }
 
-func (uc *CreateOrderUseCase) Execute(ctx context.Context, input CreateOrderInput) (*entity.Order, error) {
-	logger.Info("Processing create order request",
-		zap.Int64("user_id", input.UserID),
+func (uc *CreateOrderUseCase) Execute(ctx context.Context, input CreateOrderInput) (_ *entity.Order, err error) {
+	ctx, span := tracing.Start(ctx, "CreateOrderUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersCreate); err != nil {
+		return nil, err
+	}
+
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = userID
+
+	// Everything logged while creating the order carries the user id
+	ctx = logger.WithContext(ctx, zap.Int64("user_id", userID))
+	log := logger.FromContext(ctx)
+
+	log.Info("Processing create order request",
 		zap.Int("items_count", len(input.Items)),
 	)
 
 	if len(input.Items) == 0 {
-		logger.Warn("Attempted to create order with no items",
-			zap.Int64("user_id", input.UserID),
-		)
+		log.Warn("Attempted to create order with no items")
 		return nil, ErrEmptyOrder
 	}
 
-	// Create order items and calculate total
+	shippingAddress, billingAddress, err := resolveAddresses(ctx, input)
+	if err != nil {
+		log.Warn("Invalid order address", zap.Error(err))
+		return nil, err
+	}
+
+	// Create order items and calculate subtotal
 	orderItems := make([]entity.OrderItem, 0, len(input.Items))
-	var totalAmount float64
+	var subtotal float64
 
 	for _, item := range input.Items {
 		orderItem := entity.OrderItem{
@@ -75,9 +122,9 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context, input CreateOrderInpu
 			TotalPrice: item.TotalPrice,
 		}
 		orderItems = append(orderItems, orderItem)
-		totalAmount += item.TotalPrice
+		subtotal += item.TotalPrice
 
-		logger.Debug("Processing order item",
+		log.Debug("Processing order item",
 			zap.Int64("product_id", item.ProductID),
 			zap.Int("quantity", item.Quantity),
 			zap.Float64("unit_price", item.UnitPrice),
@@ -85,36 +132,279 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context, input CreateOrderInpu
 		)
 	}
 
+	subtotal = entity.RoundMoney(subtotal)
+
+	products, err := uc.loadProducts(ctx, orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, err := uc.applyCoupon(ctx, input.CouponCode, input.UserID, subtotal)
+	if err != nil {
+		return nil, err
+	}
+
+	shippingQuote, err := uc.selectShipping(ctx, input.ShippingMethod, orderItems, products, shippingAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// Free shipping coupons discount exactly the chosen shipping cost; every
+	// other discount reduces the price of the items.
+	var itemDiscount, discountAmount float64
+	for i := range discounts {
+		if discounts[i].Type == entity.CouponTypeFreeShipping {
+			discounts[i].Amount = shippingQuote.Cost
+		} else {
+			itemDiscount += discounts[i].Amount
+		}
+		discountAmount += discounts[i].Amount
+	}
+	discountAmount = entity.RoundMoney(discountAmount)
+
+	taxResult, err := uc.calculateTax(ctx, orderItems, products, itemDiscount, subtotal, shippingAddress)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orderItems {
+		orderItems[i].TaxRate = taxResult.Lines[i].Rate
+		orderItems[i].TaxAmount = taxResult.Lines[i].TaxAmount
+	}
+
+	totalAmount := subtotal + shippingQuote.Cost - discountAmount
+	if !taxResult.PricesIncludeTax {
+		totalAmount += taxResult.TotalTax
+	}
+	totalAmount = entity.RoundMoney(totalAmount)
+
 	order := &entity.Order{
-		UserID:       input.UserID,
-		Items:        orderItems,
-		TotalAmount:  totalAmount,
-		Status:       entity.OrderStatusPending,
-		ShippingAddr: input.ShippingAddr,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		UserID:           input.UserID,
+		Items:            orderItems,
+		Discounts:        discounts,
+		Subtotal:         subtotal,
+		DiscountAmount:   discountAmount,
+		TaxAmount:        taxResult.TotalTax,
+		PricesIncludeTax: taxResult.PricesIncludeTax,
+		TaxSummary:       entity.BuildTaxSummary(orderItems, itemDiscount, subtotal),
+		ShippingMethod:   shippingQuote.Method,
+		ShippingCost:     shippingQuote.Cost,
+		TotalAmount:      totalAmount,
+		Status:           entity.OrderStatusPending,
+		ShippingAddress:  shippingAddress,
+		BillingAddress:   billingAddress,
+		Notes:            input.Notes,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 
-	err := uc.orderRepo.CreateOrder(ctx, order)
+	err = uc.orderRepo.CreateOrder(ctx, order)
 	if err != nil {
-		logger.Error("Failed to create order",
+		log.Error("Failed to create order",
 			zap.Error(err),
-			zap.Int64("user_id", input.UserID),
 			zap.Float64("total_amount", totalAmount),
 		)
 		return nil, err
 	}
 
-	logger.Info("Order created successfully",
-		zap.Int64("order_id", order.ID),
-		zap.Int64("user_id", order.UserID),
-		zap.Float64("total_amount", order.TotalAmount),
-		zap.String("status", string(order.Status)),
+	log.Info("Order created successfully",
+		zap.Object("order", entity.RedactedOrder(*order)),
 	)
+	metrics.OrderCreated(string(order.Status), order.TotalAmount)
 
 	return order, nil
 }
 
+// loadProducts fetches the products referenced by the order items.
+func (uc *CreateOrderUseCase) loadProducts(ctx context.Context, items []entity.OrderItem) (map[int64]entity.Product, error) {
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	found, err := uc.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]entity.Product, len(found))
+	for _, product := range found {
+		products[product.ID] = product
+	}
+
+	for _, item := range items {
+		if _, ok := products[item.ProductID]; !ok {
+			logger.FromContext(ctx).Warn("Order references unknown product",
+				zap.Int64("product_id", item.ProductID),
+			)
+			return nil, ErrProductNotFound
+		}
+	}
+
+	return products, nil
+}
+
+// selectShipping quotes shipping for the order and returns the quote of the
+// requested method, or the cheapest one when no method was requested.
+func (uc *CreateOrderUseCase) selectShipping(
+	ctx context.Context,
+	method string,
+	items []entity.OrderItem,
+	products map[int64]entity.Product,
+	destination entity.Address,
+) (entity.ShippingQuote, error) {
+	req := service.ShippingQuoteRequest{
+		Destination: destination,
+		Items:       make([]service.ShippingItem, 0, len(items)),
+	}
+	for _, item := range items {
+		req.Items = append(req.Items, service.ShippingItem{
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+			WeightGrams: products[item.ProductID].WeightGrams,
+			Value:       item.TotalPrice,
+		})
+	}
+
+	quotes, err := uc.shippingProvider.Quote(ctx, req)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to quote shipping",
+			zap.Error(err),
+			zap.String("country", destination.Country),
+		)
+		return entity.ShippingQuote{}, err
+	}
+
+	if method == "" {
+		return quotes[0], nil
+	}
+	for _, quote := range quotes {
+		if quote.Method == method {
+			return quote, nil
+		}
+	}
+
+	logger.FromContext(ctx).Warn("Requested shipping method is not available",
+		zap.String("shipping_method", method),
+		zap.String("country", destination.Country),
+	)
+	return entity.ShippingQuote{}, entity.ErrShippingMethodUnavailable
+}
+
+// calculateTax taxes the order lines for the shipping destination. Item
+// discounts are allocated to the lines in proportion to their totals first,
+// so that tax is charged on what the customer actually pays.
+func (uc *CreateOrderUseCase) calculateTax(
+	ctx context.Context,
+	items []entity.OrderItem,
+	products map[int64]entity.Product,
+	discountAmount, subtotal float64,
+	destination entity.Address,
+) (*service.TaxResult, error) {
+	req := service.TaxRequest{
+		Destination: destination,
+		Lines:       make([]service.TaxableLine, len(items)),
+	}
+	for i, item := range items {
+		category := products[item.ProductID].Category
+
+		req.Lines[i] = service.TaxableLine{
+			ProductID: item.ProductID,
+			Category:  category,
+			Amount:    entity.DiscountedLineAmount(item.TotalPrice, discountAmount, subtotal),
+		}
+	}
+
+	result, err := uc.taxCalculator.Calculate(ctx, req)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to calculate tax", zap.Error(err))
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Debug("Tax calculated",
+		zap.String("jurisdiction", result.Jurisdiction),
+		zap.Float64("total_tax", result.TotalTax),
+		zap.Bool("prices_include_tax", result.PricesIncludeTax),
+	)
+
+	return result, nil
+}
+
+// applyCoupon evaluates the coupon code, if any, against the order subtotal
+// and returns the resulting discount lines. Usage limits are checked here for
+// a fast answer and enforced again atomically when the order is stored.
+func (uc *CreateOrderUseCase) applyCoupon(ctx context.Context, code string, userID int64, subtotal float64) ([]entity.OrderDiscount, error) {
+	code = entity.NormalizeCouponCode(code)
+	if code == "" {
+		return []entity.OrderDiscount{}, nil
+	}
+
+	coupon, err := uc.couponRepo.GetCouponByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entity.ErrCouponNotFound) {
+			logger.FromContext(ctx).Warn("Unknown coupon code used",
+				zap.String("code", code),
+			)
+			return nil, fmt.Errorf("%w: unknown coupon code", entity.ErrCouponNotApplicable)
+		}
+		return nil, err
+	}
+
+	redemptions, err := uc.couponRepo.CountUserRedemptions(ctx, coupon.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := coupon.Apply(subtotal, redemptions, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Warn("Coupon rejected",
+			zap.Error(err),
+			zap.String("code", code),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Debug("Coupon applied",
+		zap.String("code", code),
+		zap.Float64("discount", discount.Amount),
+	)
+
+	return []entity.OrderDiscount{discount}, nil
+}
+
+// resolveAddresses returns the validated shipping and billing addresses of
+// the input. The billing address defaults to the shipping address, and a
+// legacy free text shipping_addr is parsed when no structured address is given.
+func resolveAddresses(ctx context.Context, input CreateOrderInput) (entity.Address, entity.Address, error) {
+	var shipping entity.Address
+
+	switch {
+	case input.ShippingAddress != nil:
+		shipping = *input.ShippingAddress
+		shipping.Normalize()
+		if err := shipping.Validate(); err != nil {
+			return entity.Address{}, entity.Address{}, err
+		}
+	case input.ShippingAddr != "":
+		logger.FromContext(ctx).Warn("Deprecated shipping_addr used, parsing free text address")
+		shipping = entity.ParseAddress(input.ShippingAddr)
+		shipping.Normalize()
+	default:
+		return entity.Address{}, entity.Address{}, ErrMissingAddress
+	}
+
+	if input.BillingAddress == nil {
+		return shipping, shipping, nil
+	}
+
+	billing := *input.BillingAddress
+	billing.Normalize()
+	if err := billing.Validate(); err != nil {
+		return entity.Address{}, entity.Address{}, err
+	}
+
+	return shipping, billing, nil
+}
+
 //type CreateOrderInput struct {
 //	UserID       primitive.ObjectID `json:"user_id"`
 //	Items        []entity.OrderItem `json:"items"`
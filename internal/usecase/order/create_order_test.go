@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+type stubProductRepo struct {
+	products []entity.Product
+}
+
+func (r stubProductRepo) GetProductsByIDs(context.Context, []int64) ([]entity.Product, error) {
+	return r.products, nil
+}
+
+// stubCouponRepo serves one coupon; the methods CreateOrderUseCase does not
+// call are left to the nil interface.
+type stubCouponRepo struct {
+	repository.CouponRepository
+	coupon *entity.Coupon
+}
+
+func (r stubCouponRepo) GetCouponByCode(_ context.Context, code string) (*entity.Coupon, error) {
+	if r.coupon == nil || r.coupon.Code != code {
+		return nil, entity.ErrCouponNotFound
+	}
+	return r.coupon, nil
+}
+
+func (r stubCouponRepo) CountUserRedemptions(context.Context, int64, int64) (int, error) {
+	return 0, nil
+}
+
+func TestCreateOrderLogsRejectedCoupon(t *testing.T) {
+	coupon := &entity.Coupon{
+		ID:            1,
+		Code:          "BIGSPENDER",
+		Type:          entity.CouponTypeFixedAmount,
+		Value:         10,
+		MinOrderValue: 100,
+		Active:        true,
+	}
+	uc := NewCreateOrderUseCase(nil,
+		stubProductRepo{products: []entity.Product{{ID: 7}}},
+		stubCouponRepo{coupon: coupon},
+		nil, nil,
+	)
+
+	var input CreateOrderInput
+	input.Items = append(input.Items, struct {
+		ProductID  int64   `json:"product_id"`
+		Quantity   int     `json:"quantity"`
+		UnitPrice  float64 `json:"unit_price"`
+		TotalPrice float64 `json:"total_price"`
+	}{ProductID: 7, Quantity: 1, UnitPrice: 20, TotalPrice: 20})
+	input.ShippingAddress = &entity.Address{Name: "Jane Doe", Lines: []string{"1 Main St"}, City: "Dubai", Country: "AE"}
+	input.CouponCode = "bigspender"
+
+	ctx := identity.WithIdentity(context.Background(), &identity.Identity{
+		Subject:     "42",
+		UserID:      42,
+		Permissions: []string{string(authz.OrdersCreate)},
+	})
+	ctx, logs := logger.Observe(ctx, zapcore.WarnLevel)
+
+	_, err := uc.Execute(ctx, input)
+	if !errors.Is(err, entity.ErrCouponNotApplicable) {
+		t.Fatalf("Execute: got %v, want ErrCouponNotApplicable", err)
+	}
+
+	rejected := logs.FilterMessage("Coupon rejected").All()
+	if len(rejected) != 1 {
+		t.Fatalf("expected the rejected coupon to be logged once, got %d entries", len(rejected))
+	}
+	fields := rejected[0].ContextMap()
+	if fields["code"] != "BIGSPENDER" || fields["user_id"] != int64(42) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
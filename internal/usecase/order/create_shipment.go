@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type CreateShipmentInput struct {
+	OrderID        int64      `json:"-"`
+	Carrier        string     `json:"carrier"`
+	TrackingNumber string     `json:"tracking_number"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	Items          []struct {
+		OrderItemID int64 `json:"order_item_id"`
+		Quantity    int   `json:"quantity"`
+	} `json:"items"`
+}
+
+// ShipmentOutput is a shipment together with the order status it led to.
+type ShipmentOutput struct {
+	Shipment    *entity.Shipment   `json:"shipment"`
+	OrderStatus entity.OrderStatus `json:"order_status"`
+}
+
+type CreateShipmentUseCase struct {
+	shipmentRepo repository.ShipmentRepository
+}
+
+func NewCreateShipmentUseCase(
+	shipmentRepo repository.ShipmentRepository,
+) *CreateShipmentUseCase {
+	return &CreateShipmentUseCase{
+		shipmentRepo: shipmentRepo,
+	}
+}
+
+func (uc *CreateShipmentUseCase) Execute(ctx context.Context, input CreateShipmentInput) (_ *ShipmentOutput, err error) {
+	ctx, span := tracing.Start(ctx, "CreateShipmentUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ShipmentsWrite); err != nil {
+		return nil, err
+	}
+
+	shipment := &entity.Shipment{
+		OrderID:        input.OrderID,
+		Carrier:        strings.TrimSpace(input.Carrier),
+		TrackingNumber: strings.TrimSpace(input.TrackingNumber),
+		ShippedAt:      time.Now(),
+		Items:          make([]entity.ShipmentItem, 0, len(input.Items)),
+	}
+	if input.ShippedAt != nil {
+		shipment.ShippedAt = *input.ShippedAt
+	}
+	for _, item := range input.Items {
+		shipment.Items = append(shipment.Items, entity.ShipmentItem{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	status, err := uc.shipmentRepo.CreateShipment(ctx, shipment)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to create shipment",
+			zap.Error(err),
+			zap.Int64("order_id", input.OrderID),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Shipment created",
+		zap.Int64("order_id", shipment.OrderID),
+		zap.Int64("shipment_id", shipment.ID),
+		zap.String("carrier", shipment.Carrier),
+		zap.String("order_status", string(status)),
+	)
+
+	return &ShipmentOutput{
+		Shipment:    shipment,
+		OrderStatus: status,
+	}, nil
+}
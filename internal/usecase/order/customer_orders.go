@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/identity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+)
+
+type ListCustomerOrdersOutput struct {
+	Orders     []entity.UserOrderSummary `json:"data"`
+	Pagination entity.Pagination         `json:"pagination"`
+}
+
+// CustomerOrdersUseCase lets the authenticated user read their own orders.
+// The user is always taken from the context, never from the request.
+type CustomerOrdersUseCase struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewCustomerOrdersUseCase(
+	orderRepo repository.OrderRepository,
+) *CustomerOrdersUseCase {
+	return &CustomerOrdersUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+func (uc *CustomerOrdersUseCase) List(ctx context.Context, page, pageSize int) (_ *ListCustomerOrdersOutput, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerOrdersUseCase.List")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersReadOwn); err != nil {
+		return nil, err
+	}
+
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	if page < 1 {
+		return nil, ErrInvalidPage
+	}
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	orders, total, err := uc.orderRepo.ListOrdersByUser(ctx, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListCustomerOrdersOutput{
+		Orders:     orders,
+		Pagination: entity.NewPagination(page, pageSize, total),
+	}, nil
+}
+
+// Get returns one of the user's orders. Other users' orders are reported as
+// not found so their ids cannot be probed.
+func (uc *CustomerOrdersUseCase) Get(ctx context.Context, orderID int64) (_ *entity.Order, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerOrdersUseCase.Get")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersReadOwn); err != nil {
+		return nil, err
+	}
+
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := uc.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, orderID)
+	}
+
+	return order, nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+)
+
+// ListShipmentsUseCase returns the shipments of an order.
+type ListShipmentsUseCase struct {
+	shipmentRepo repository.ShipmentRepository
+}
+
+func NewListShipmentsUseCase(
+	shipmentRepo repository.ShipmentRepository,
+) *ListShipmentsUseCase {
+	return &ListShipmentsUseCase{
+		shipmentRepo: shipmentRepo,
+	}
+}
+
+func (uc *ListShipmentsUseCase) Execute(ctx context.Context, orderID int64) (_ []entity.Shipment, err error) {
+	ctx, span := tracing.Start(ctx, "ListShipmentsUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ShipmentsRead); err != nil {
+		return nil, err
+	}
+	return uc.shipmentRepo.ListShipments(ctx, orderID)
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrEmptySearchQuery = errors.New("search query must not be empty")
+	ErrInvalidPage      = errors.New("page must be greater than zero")
+	ErrInvalidPageSize  = errors.New("page size must be between 1 and 100")
+)
+
+type SearchOrdersInput struct {
+	Query    string
+	Page     int
+	PageSize int
+}
+
+type SearchOrdersOutput struct {
+	Results    []entity.OrderSearchResult `json:"data"`
+	Pagination entity.Pagination          `json:"pagination"`
+}
+
+type SearchOrdersUseCase struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewSearchOrdersUseCase(
+	orderRepo repository.OrderRepository,
+) *SearchOrdersUseCase {
+	return &SearchOrdersUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+func (uc *SearchOrdersUseCase) Execute(ctx context.Context, input SearchOrdersInput) (_ *SearchOrdersOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SearchOrdersUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersRead); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	if input.Page == 0 {
+		input.Page = 1
+	}
+	if input.PageSize == 0 {
+		input.PageSize = DefaultPageSize
+	}
+	if input.Page < 1 {
+		return nil, ErrInvalidPage
+	}
+	if input.PageSize < 1 || input.PageSize > MaxPageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	logger.FromContext(ctx).Debug("Searching orders",
+		zap.String("query", query),
+		zap.Int("page", input.Page),
+		zap.Int("page_size", input.PageSize),
+	)
+
+	offset := (input.Page - 1) * input.PageSize
+	results, total, err := uc.orderRepo.SearchOrders(ctx, query, input.PageSize, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to search orders",
+			zap.Error(err),
+			zap.String("query", query),
+		)
+		return nil, err
+	}
+
+	return &SearchOrdersOutput{
+		Results:    results,
+		Pagination: entity.NewPagination(input.Page, input.PageSize, total),
+	}, nil
+}
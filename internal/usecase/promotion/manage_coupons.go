@@ -0,0 +1,191 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidPagination = errors.New("page must be positive and page size between 1 and 100")
+
+type CouponInput struct {
+	Code           string            `json:"code"`
+	Description    string            `json:"description"`
+	Type           entity.CouponType `json:"type"`
+	Value          float64           `json:"value"`
+	MinOrderValue  float64           `json:"min_order_value"`
+	ValidFrom      *time.Time        `json:"valid_from"`
+	ValidUntil     *time.Time        `json:"valid_until"`
+	MaxUses        *int              `json:"max_uses"`
+	MaxUsesPerUser *int              `json:"max_uses_per_user"`
+	Active         *bool             `json:"active"`
+}
+
+type ListCouponsOutput struct {
+	Coupons    []entity.Coupon   `json:"data"`
+	Pagination entity.Pagination `json:"pagination"`
+}
+
+// ManageCouponsUseCase implements the admin operations on coupons.
+type ManageCouponsUseCase struct {
+	couponRepo repository.CouponRepository
+}
+
+func NewManageCouponsUseCase(
+	couponRepo repository.CouponRepository,
+) *ManageCouponsUseCase {
+	return &ManageCouponsUseCase{
+		couponRepo: couponRepo,
+	}
+}
+
+func (uc *ManageCouponsUseCase) Create(ctx context.Context, input CouponInput) (_ *entity.Coupon, err error) {
+	ctx, span := tracing.Start(ctx, "ManageCouponsUseCase.Create")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.CouponsManage); err != nil {
+		return nil, err
+	}
+
+	coupon := &entity.Coupon{Active: true}
+	input.applyTo(coupon)
+
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.couponRepo.CreateCoupon(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Coupon created",
+		zap.Int64("coupon_id", coupon.ID),
+		zap.String("code", coupon.Code),
+		zap.String("type", string(coupon.Type)),
+	)
+
+	return coupon, nil
+}
+
+func (uc *ManageCouponsUseCase) Update(ctx context.Context, couponID int64, input CouponInput) (_ *entity.Coupon, err error) {
+	ctx, span := tracing.Start(ctx, "ManageCouponsUseCase.Update")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.CouponsManage); err != nil {
+		return nil, err
+	}
+
+	coupon, err := uc.couponRepo.GetCouponByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.applyTo(coupon)
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.couponRepo.UpdateCoupon(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Coupon updated",
+		zap.Int64("coupon_id", coupon.ID),
+		zap.String("code", coupon.Code),
+	)
+
+	return coupon, nil
+}
+
+func (uc *ManageCouponsUseCase) Get(ctx context.Context, couponID int64) (_ *entity.Coupon, err error) {
+	ctx, span := tracing.Start(ctx, "ManageCouponsUseCase.Get")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.CouponsManage); err != nil {
+		return nil, err
+	}
+	return uc.couponRepo.GetCouponByID(ctx, couponID)
+}
+
+func (uc *ManageCouponsUseCase) List(ctx context.Context, page, pageSize int) (_ *ListCouponsOutput, err error) {
+	ctx, span := tracing.Start(ctx, "ManageCouponsUseCase.List")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.CouponsManage); err != nil {
+		return nil, err
+	}
+
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	if page < 1 || pageSize < 1 || pageSize > MaxPageSize {
+		return nil, ErrInvalidPagination
+	}
+
+	coupons, total, err := uc.couponRepo.ListCoupons(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListCouponsOutput{
+		Coupons:    coupons,
+		Pagination: entity.NewPagination(page, pageSize, total),
+	}, nil
+}
+
+// Deactivate disables a coupon. Coupons are never deleted because past
+// orders and redemptions reference them.
+func (uc *ManageCouponsUseCase) Deactivate(ctx context.Context, couponID int64) (_ *entity.Coupon, err error) {
+	ctx, span := tracing.Start(ctx, "ManageCouponsUseCase.Deactivate")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.CouponsManage); err != nil {
+		return nil, err
+	}
+
+	coupon, err := uc.couponRepo.GetCouponByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon.Active = false
+	if err := uc.couponRepo.UpdateCoupon(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Coupon deactivated",
+		zap.Int64("coupon_id", coupon.ID),
+		zap.String("code", coupon.Code),
+	)
+
+	return coupon, nil
+}
+
+func (in CouponInput) applyTo(coupon *entity.Coupon) {
+	coupon.Code = entity.NormalizeCouponCode(in.Code)
+	coupon.Description = in.Description
+	coupon.Type = in.Type
+	coupon.Value = in.Value
+	coupon.MinOrderValue = in.MinOrderValue
+	coupon.ValidFrom = in.ValidFrom
+	coupon.ValidUntil = in.ValidUntil
+	coupon.MaxUses = in.MaxUses
+	coupon.MaxUsesPerUser = in.MaxUsesPerUser
+	if in.Active != nil {
+		coupon.Active = *in.Active
+	}
+}
@@ -0,0 +1,72 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidPagination = errors.New("page must be positive and page size between 1 and 100")
+
+type ListUserOrdersInput struct {
+	UserID   int64
+	Page     int
+	PageSize int
+}
+
+type ListUserOrdersOutput struct {
+	Orders     []entity.UserOrderSummary `json:"data"`
+	Pagination entity.Pagination         `json:"pagination"`
+}
+
+// ListUserOrdersUseCase serves a customer's order history from the
+// user_order_summaries read model. Orders show up a few seconds after they
+// are placed.
+type ListUserOrdersUseCase struct {
+	historyRepo repository.OrderHistoryRepository
+}
+
+func NewListUserOrdersUseCase(
+	historyRepo repository.OrderHistoryRepository,
+) *ListUserOrdersUseCase {
+	return &ListUserOrdersUseCase{
+		historyRepo: historyRepo,
+	}
+}
+
+func (uc *ListUserOrdersUseCase) Execute(ctx context.Context, input ListUserOrdersInput) (_ *ListUserOrdersOutput, err error) {
+	ctx, span := tracing.Start(ctx, "ListUserOrdersUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.OrdersRead); err != nil {
+		return nil, err
+	}
+
+	if input.Page == 0 {
+		input.Page = 1
+	}
+	if input.PageSize == 0 {
+		input.PageSize = DefaultPageSize
+	}
+	if input.Page < 1 || input.PageSize < 1 || input.PageSize > MaxPageSize {
+		return nil, ErrInvalidPagination
+	}
+
+	orders, total, err := uc.historyRepo.ListUserOrders(ctx, input.UserID, input.PageSize, (input.Page-1)*input.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListUserOrdersOutput{
+		Orders:     orders,
+		Pagination: entity.NewPagination(input.Page, input.PageSize, total),
+	}, nil
+}
@@ -0,0 +1,200 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"time"
+)
+
+const (
+	DefaultTopProducts = 10
+	MaxTopProducts     = 100
+)
+
+var (
+	ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Europe/Riga")
+	ErrInvalidInterval = errors.New("interval must be day, week or month")
+	ErrInvalidRanking  = errors.New("by must be quantity or revenue")
+	ErrInvalidLimit    = errors.New("limit must be between 1 and 100")
+)
+
+// ReportInput selects the orders placed on an inclusive range of days in
+// Timezone (UTC when empty). Empty dates default to the last
+// DefaultReportDays days.
+type ReportInput struct {
+	From     string
+	To       string
+	Timezone string
+}
+
+type RevenueInput struct {
+	ReportInput
+	Interval entity.ReportInterval
+}
+
+type TopProductsInput struct {
+	ReportInput
+	By    entity.TopProductsOrder
+	Limit int
+}
+
+// ReportPeriod echoes the range a report covers.
+type ReportPeriod struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone"`
+}
+
+type RevenueOutput struct {
+	ReportPeriod
+	Interval entity.ReportInterval  `json:"interval"`
+	Buckets  []entity.RevenueBucket `json:"data"`
+}
+
+type TopProductsOutput struct {
+	ReportPeriod
+	By       entity.TopProductsOrder `json:"by"`
+	Products []entity.TopProduct     `json:"data"`
+}
+
+type StatusFunnelOutput struct {
+	ReportPeriod
+	Funnel *entity.StatusFunnel `json:"data"`
+}
+
+// SalesAnalyticsUseCase serves the management reports, aggregated from the
+// orders tables on request.
+type SalesAnalyticsUseCase struct {
+	analyticsRepo repository.AnalyticsRepository
+}
+
+func NewSalesAnalyticsUseCase(
+	analyticsRepo repository.AnalyticsRepository,
+) *SalesAnalyticsUseCase {
+	return &SalesAnalyticsUseCase{
+		analyticsRepo: analyticsRepo,
+	}
+}
+
+func (uc *SalesAnalyticsUseCase) Revenue(ctx context.Context, input RevenueInput) (_ *RevenueOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SalesAnalyticsUseCase.Revenue")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+
+	if input.Interval == "" {
+		input.Interval = entity.ReportIntervalDay
+	}
+	if !input.Interval.Valid() {
+		return nil, ErrInvalidInterval
+	}
+
+	rng, period, err := input.reportRange(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := uc.analyticsRepo.Revenue(ctx, rng, input.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevenueOutput{
+		ReportPeriod: period,
+		Interval:     input.Interval,
+		Buckets:      buckets,
+	}, nil
+}
+
+func (uc *SalesAnalyticsUseCase) TopProducts(ctx context.Context, input TopProductsInput) (_ *TopProductsOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SalesAnalyticsUseCase.TopProducts")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+
+	if input.By == "" {
+		input.By = entity.TopProductsByQuantity
+	}
+	if input.By != entity.TopProductsByQuantity && input.By != entity.TopProductsByRevenue {
+		return nil, ErrInvalidRanking
+	}
+	if input.Limit == 0 {
+		input.Limit = DefaultTopProducts
+	}
+	if input.Limit < 1 || input.Limit > MaxTopProducts {
+		return nil, ErrInvalidLimit
+	}
+
+	rng, period, err := input.reportRange(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := uc.analyticsRepo.TopProducts(ctx, rng, input.By, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopProductsOutput{
+		ReportPeriod: period,
+		By:           input.By,
+		Products:     products,
+	}, nil
+}
+
+func (uc *SalesAnalyticsUseCase) StatusFunnel(ctx context.Context, input ReportInput) (_ *StatusFunnelOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SalesAnalyticsUseCase.StatusFunnel")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+
+	rng, period, err := input.reportRange(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	funnel, err := uc.analyticsRepo.StatusFunnel(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusFunnelOutput{
+		ReportPeriod: period,
+		Funnel:       funnel,
+	}, nil
+}
+
+func (in ReportInput) reportRange(now time.Time) (entity.ReportRange, ReportPeriod, error) {
+	loc := time.UTC
+	if in.Timezone != "" {
+		l, err := time.LoadLocation(in.Timezone)
+		// Local is the timezone of the host, which Postgres does not know
+		if err != nil || in.Timezone == "Local" {
+			return entity.ReportRange{}, ReportPeriod{}, ErrInvalidTimezone
+		}
+		loc = l
+	}
+
+	from, to, err := parseDateRange(in.From, in.To, loc, now)
+	if err != nil {
+		return entity.ReportRange{}, ReportPeriod{}, err
+	}
+
+	rng := entity.ReportRange{From: from, To: to.AddDate(0, 0, 1), Location: loc}
+	period := ReportPeriod{
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Timezone: loc.String(),
+	}
+	return rng, period, nil
+}
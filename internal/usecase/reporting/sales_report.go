@@ -0,0 +1,148 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"time"
+)
+
+const (
+	// DefaultReportDays is the range reported when no dates are given.
+	DefaultReportDays = 30
+	MaxReportDays     = 366
+)
+
+var ErrInvalidDateRange = errors.New("from and to must be dates (YYYY-MM-DD), from not after to, covering at most 366 days")
+
+// SalesReportInput holds an inclusive range of days in UTC. Empty dates
+// default to the last DefaultReportDays days.
+type SalesReportInput struct {
+	From string
+	To   string
+}
+
+type DailySalesOutput struct {
+	From  string              `json:"from"`
+	To    string              `json:"to"`
+	Sales []entity.DailySales `json:"data"`
+}
+
+type DailyCategorySalesOutput struct {
+	From  string                      `json:"from"`
+	To    string                      `json:"to"`
+	Sales []entity.DailyCategorySales `json:"data"`
+}
+
+// SalesReportUseCase serves the sales dashboards from the daily_sales read
+// models.
+type SalesReportUseCase struct {
+	salesRepo      repository.SalesReportRepository
+	projectionRepo repository.ProjectionRepository
+}
+
+func NewSalesReportUseCase(
+	salesRepo repository.SalesReportRepository,
+	projectionRepo repository.ProjectionRepository,
+) *SalesReportUseCase {
+	return &SalesReportUseCase{
+		salesRepo:      salesRepo,
+		projectionRepo: projectionRepo,
+	}
+}
+
+func (uc *SalesReportUseCase) DailySales(ctx context.Context, input SalesReportInput) (_ *DailySalesOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SalesReportUseCase.DailySales")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+
+	from, to, err := input.dateRange(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := uc.salesRepo.DailySales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DailySalesOutput{
+		From:  from.Format(time.DateOnly),
+		To:    to.Format(time.DateOnly),
+		Sales: sales,
+	}, nil
+}
+
+func (uc *SalesReportUseCase) DailyCategorySales(ctx context.Context, input SalesReportInput) (_ *DailyCategorySalesOutput, err error) {
+	ctx, span := tracing.Start(ctx, "SalesReportUseCase.DailyCategorySales")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+
+	from, to, err := input.dateRange(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := uc.salesRepo.DailyCategorySales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DailyCategorySalesOutput{
+		From:  from.Format(time.DateOnly),
+		To:    to.Format(time.DateOnly),
+		Sales: sales,
+	}, nil
+}
+
+// ProjectionLag tells how far each read model trails the order events.
+func (uc *SalesReportUseCase) ProjectionLag(ctx context.Context) (_ []entity.ProjectionLag, err error) {
+	ctx, span := tracing.Start(ctx, "SalesReportUseCase.ProjectionLag")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReportsRead); err != nil {
+		return nil, err
+	}
+	return uc.projectionRepo.ProjectionLag(ctx)
+}
+
+func (in SalesReportInput) dateRange(now time.Time) (time.Time, time.Time, error) {
+	return parseDateRange(in.From, in.To, time.UTC, now)
+}
+
+// parseDateRange parses an inclusive range of YYYY-MM-DD dates in loc and
+// returns the midnights starting its first and last day.
+func parseDateRange(fromDate, toDate string, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	n := now.In(loc)
+	to := time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, loc)
+	if toDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, toDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateRange
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -(DefaultReportDays - 1))
+	if fromDate != "" {
+		f, err := time.ParseInLocation(time.DateOnly, fromDate, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateRange
+		}
+		from = f
+	}
+
+	if from.After(to) || from.AddDate(0, 0, MaxReportDays).Before(to.AddDate(0, 0, 1)) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return from, to, nil
+}
@@ -0,0 +1,255 @@
+package returns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var ErrReturnNotReceived = errors.New("refunds for a return can only be issued once the goods are received")
+
+type IssueRefundInput struct {
+	OrderID  int64  `json:"-"`
+	ReturnID *int64 `json:"return_id"`
+	// Amount overrides the computed amount for partial or goodwill refunds.
+	Amount          *float64 `json:"amount"`
+	IncludeShipping bool     `json:"include_shipping"`
+	Reason          string   `json:"reason"`
+}
+
+type UpdateRefundInput struct {
+	RefundID  int64               `json:"-"`
+	Status    entity.RefundStatus `json:"status"`
+	Reference string              `json:"reference"`
+}
+
+// IssueRefundUseCase records refunds against orders and settles them.
+type IssueRefundUseCase struct {
+	orderRepo   repository.OrderRepository
+	returnRepo  repository.ReturnRepository
+	refundRepo  repository.RefundRepository
+	paymentRepo repository.PaymentRepository
+	gateway     service.PaymentGateway
+}
+
+func NewIssueRefundUseCase(
+	orderRepo repository.OrderRepository,
+	returnRepo repository.ReturnRepository,
+	refundRepo repository.RefundRepository,
+	paymentRepo repository.PaymentRepository,
+	gateway service.PaymentGateway,
+) *IssueRefundUseCase {
+	return &IssueRefundUseCase{
+		orderRepo:   orderRepo,
+		returnRepo:  returnRepo,
+		refundRepo:  refundRepo,
+		paymentRepo: paymentRepo,
+		gateway:     gateway,
+	}
+}
+
+// Execute records a refund and, when the order was paid through the
+// gateway, returns the money through it. Without an explicit amount a
+// refund for a return covers the returned items, and a refund without a
+// return covers whatever is left of the order total.
+func (uc *IssueRefundUseCase) Execute(ctx context.Context, input IssueRefundInput) (_ *entity.Refund, err error) {
+	ctx, span := tracing.Start(ctx, "IssueRefundUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.RefundsIssue); err != nil {
+		return nil, err
+	}
+
+	order, err := uc.orderRepo.GetOrderByID(ctx, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &entity.Refund{
+		OrderID:   order.ID,
+		ReturnID:  input.ReturnID,
+		Reason:    strings.TrimSpace(input.Reason),
+		CreatedAt: time.Now(),
+	}
+	if input.IncludeShipping {
+		refund.ShippingAmount = order.NetShippingCost()
+	}
+
+	if input.ReturnID != nil {
+		amount, err := uc.returnAmount(ctx, order, *input.ReturnID)
+		if err != nil {
+			return nil, err
+		}
+		refund.Amount = entity.RoundMoney(amount + refund.ShippingAmount)
+	} else {
+		refunds, err := uc.refundRepo.ListRefunds(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		refund.Amount = order.TotalAmount
+		for _, r := range refunds {
+			if r.Status != entity.RefundStatusFailed {
+				refund.Amount -= r.Amount
+			}
+		}
+		refund.Amount = entity.RoundMoney(refund.Amount)
+		refund.ShippingAmount = min(refund.ShippingAmount, refund.Amount)
+	}
+	if input.Amount != nil {
+		amount := entity.RoundMoney(*input.Amount)
+		// A return is refunded once, so its refund may be lowered but never
+		// raised above the returned items and shipping
+		if input.ReturnID != nil && amount > refund.Amount {
+			return nil, fmt.Errorf("%w: amount exceeds the %.2f of the return", entity.ErrInvalidRefund, refund.Amount)
+		}
+		refund.Amount = amount
+		refund.ShippingAmount = min(refund.ShippingAmount, refund.Amount)
+	}
+
+	// The repository re-checks the amount against earlier refunds with the order locked
+	if err := uc.refundRepo.CreateRefund(ctx, refund); err != nil {
+		logger.FromContext(ctx).Warn("Failed to create refund",
+			zap.Error(err),
+			zap.Int64("order_id", order.ID),
+		)
+		return nil, err
+	}
+
+	if err := uc.refundPayment(ctx, refund); err != nil {
+		logger.FromContext(ctx).Warn("Failed to refund payment",
+			zap.Error(err),
+			zap.Int64("refund_id", refund.ID),
+			zap.Int64("order_id", refund.OrderID),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Refund recorded",
+		zap.Int64("refund_id", refund.ID),
+		zap.Int64("order_id", refund.OrderID),
+		zap.Float64("amount", refund.Amount),
+		zap.Float64("shipping_amount", refund.ShippingAmount),
+		zap.String("status", string(refund.Status)),
+	)
+
+	return refund, nil
+}
+
+// refundPayment returns the money of a pending refund through the gateway
+// that captured the order's payment. Orders paid otherwise keep the refund
+// pending until UpdateStatus settles it. A refund the gateway declines is
+// failed, so it may be issued again; after a timeout it stays pending, as
+// the gateway may have refunded all the same.
+func (uc *IssueRefundUseCase) refundPayment(ctx context.Context, refund *entity.Refund) error {
+	payments, err := uc.paymentRepo.ListPayments(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+	var captured *entity.Payment
+	for i := range payments {
+		if payments[i].Status == entity.PaymentStatusCaptured {
+			captured = &payments[i]
+		}
+	}
+	if captured == nil {
+		return nil
+	}
+
+	reference, err := uc.gateway.Refund(ctx, captured.CaptureID, refund.Amount)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, entity.ErrPaymentTimeout) {
+			err = fmt.Errorf("%w: %v", entity.ErrPaymentTimeout, err)
+		}
+		if errors.Is(err, entity.ErrPaymentTimeout) {
+			logger.FromContext(ctx).Warn("Refund left pending after a gateway timeout",
+				zap.Error(err),
+				zap.Int64("refund_id", refund.ID),
+			)
+			return err
+		}
+
+		if transitionErr := refund.TransitionTo(entity.RefundStatusFailed, time.Now()); transitionErr != nil {
+			return errors.Join(err, transitionErr)
+		}
+		if updateErr := uc.refundRepo.UpdateRefundStatus(context.WithoutCancel(ctx), refund, entity.RefundStatusPending); updateErr != nil {
+			return errors.Join(err, updateErr)
+		}
+		return err
+	}
+
+	if err := refund.TransitionTo(entity.RefundStatusCompleted, time.Now()); err != nil {
+		return err
+	}
+	refund.Reference = reference
+	return uc.refundRepo.UpdateRefundStatus(context.WithoutCancel(ctx), refund, entity.RefundStatusPending)
+}
+
+func (uc *IssueRefundUseCase) returnAmount(ctx context.Context, order *entity.Order, returnID int64) (float64, error) {
+	ret, err := uc.returnRepo.GetReturn(ctx, returnID)
+	if err != nil {
+		return 0, err
+	}
+	if ret.OrderID != order.ID {
+		return 0, fmt.Errorf("%w: return %d does not belong to order %d", entity.ErrInvalidRefund, returnID, order.ID)
+	}
+	if ret.Status != entity.ReturnStatusReceived {
+		return 0, ErrReturnNotReceived
+	}
+
+	items := make(map[int64]entity.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+
+	var amount float64
+	for _, item := range ret.Items {
+		amount += order.ItemRefundAmount(items[item.OrderItemID], item.Quantity)
+	}
+	return amount, nil
+}
+
+// UpdateStatus settles a pending refund once the payment provider confirms
+// or rejects it.
+func (uc *IssueRefundUseCase) UpdateStatus(ctx context.Context, input UpdateRefundInput) (_ *entity.Refund, err error) {
+	ctx, span := tracing.Start(ctx, "IssueRefundUseCase.UpdateStatus")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.RefundsIssue); err != nil {
+		return nil, err
+	}
+
+	refund, err := uc.refundRepo.GetRefund(ctx, input.RefundID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := refund.Status
+	if err := refund.TransitionTo(input.Status, time.Now()); err != nil {
+		return nil, err
+	}
+	if reference := strings.TrimSpace(input.Reference); reference != "" {
+		refund.Reference = reference
+	}
+
+	if err := uc.refundRepo.UpdateRefundStatus(ctx, refund, from); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Refund settled",
+		zap.Int64("refund_id", refund.ID),
+		zap.Int64("order_id", refund.OrderID),
+		zap.String("status", string(refund.Status)),
+		zap.String("reference", refund.Reference),
+	)
+
+	return refund, nil
+}
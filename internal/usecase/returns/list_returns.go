@@ -0,0 +1,67 @@
+package returns
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+)
+
+// ListReturnsUseCase serves the read side of returns and refunds.
+type ListReturnsUseCase struct {
+	orderRepo  repository.OrderRepository
+	returnRepo repository.ReturnRepository
+	refundRepo repository.RefundRepository
+}
+
+func NewListReturnsUseCase(
+	orderRepo repository.OrderRepository,
+	returnRepo repository.ReturnRepository,
+	refundRepo repository.RefundRepository,
+) *ListReturnsUseCase {
+	return &ListReturnsUseCase{
+		orderRepo:  orderRepo,
+		returnRepo: returnRepo,
+		refundRepo: refundRepo,
+	}
+}
+
+func (uc *ListReturnsUseCase) GetReturn(ctx context.Context, returnID int64) (_ *entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "ListReturnsUseCase.GetReturn")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsRead); err != nil {
+		return nil, err
+	}
+	return uc.returnRepo.GetReturn(ctx, returnID)
+}
+
+func (uc *ListReturnsUseCase) ListReturns(ctx context.Context, orderID int64) (_ []entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "ListReturnsUseCase.ListReturns")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsRead); err != nil {
+		return nil, err
+	}
+
+	// Distinguish an unknown order from an order without returns
+	if _, err := uc.orderRepo.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return uc.returnRepo.ListReturns(ctx, orderID)
+}
+
+func (uc *ListReturnsUseCase) ListRefunds(ctx context.Context, orderID int64) (_ []entity.Refund, err error) {
+	ctx, span := tracing.Start(ctx, "ListReturnsUseCase.ListRefunds")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.RefundsRead); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.orderRepo.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return uc.refundRepo.ListRefunds(ctx, orderID)
+}
@@ -0,0 +1,90 @@
+package returns
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type RequestReturnInput struct {
+	OrderID int64  `json:"-"`
+	Comment string `json:"comment"`
+	Items   []struct {
+		OrderItemID int64               `json:"order_item_id"`
+		Quantity    int                 `json:"quantity"`
+		Reason      entity.ReturnReason `json:"reason"`
+	} `json:"items"`
+}
+
+type RequestReturnUseCase struct {
+	orderRepo  repository.OrderRepository
+	returnRepo repository.ReturnRepository
+}
+
+func NewRequestReturnUseCase(
+	orderRepo repository.OrderRepository,
+	returnRepo repository.ReturnRepository,
+) *RequestReturnUseCase {
+	return &RequestReturnUseCase{
+		orderRepo:  orderRepo,
+		returnRepo: returnRepo,
+	}
+}
+
+func (uc *RequestReturnUseCase) Execute(ctx context.Context, input RequestReturnInput) (_ *entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "RequestReturnUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsRequest); err != nil {
+		return nil, err
+	}
+
+	// Customers may only return their own orders
+	order, err := uc.orderRepo.GetOrderByID(ctx, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !authz.CanAccess(ctx, order.UserID, authz.OrdersRead) {
+		return nil, fmt.Errorf("%w: %d", entity.ErrOrderNotFound, input.OrderID)
+	}
+
+	now := time.Now()
+	ret := &entity.ReturnRequest{
+		OrderID:   input.OrderID,
+		Comment:   strings.TrimSpace(input.Comment),
+		Items:     make([]entity.ReturnItem, 0, len(input.Items)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, item := range input.Items {
+		ret.Items = append(ret.Items, entity.ReturnItem{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+			Reason:      item.Reason,
+		})
+	}
+
+	// Validation happens in the repository with the order locked
+	if err := uc.returnRepo.CreateReturn(ctx, ret); err != nil {
+		logger.FromContext(ctx).Warn("Failed to create return",
+			zap.Error(err),
+			zap.Int64("order_id", input.OrderID),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Return requested",
+		zap.Int64("return_id", ret.ID),
+		zap.Int64("order_id", ret.OrderID),
+		zap.Int("items", len(ret.Items)),
+	)
+
+	return ret, nil
+}
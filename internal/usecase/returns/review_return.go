@@ -0,0 +1,109 @@
+package returns
+
+import (
+	"context"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type ReviewReturnInput struct {
+	ReturnID int64  `json:"-"`
+	Note     string `json:"note"`
+	// Restock puts the received items back in stock. Only used when
+	// receiving a return; damaged goods are usually not restocked.
+	Restock bool `json:"restock"`
+}
+
+// ReviewReturnUseCase implements the operator steps of the returns workflow.
+type ReviewReturnUseCase struct {
+	returnRepo repository.ReturnRepository
+}
+
+func NewReviewReturnUseCase(
+	returnRepo repository.ReturnRepository,
+) *ReviewReturnUseCase {
+	return &ReviewReturnUseCase{
+		returnRepo: returnRepo,
+	}
+}
+
+func (uc *ReviewReturnUseCase) Approve(ctx context.Context, input ReviewReturnInput) (_ *entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewReturnUseCase.Approve")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsReview); err != nil {
+		return nil, err
+	}
+	return uc.transition(ctx, input, entity.ReturnStatusApproved)
+}
+
+func (uc *ReviewReturnUseCase) Reject(ctx context.Context, input ReviewReturnInput) (_ *entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewReturnUseCase.Reject")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsReview); err != nil {
+		return nil, err
+	}
+	return uc.transition(ctx, input, entity.ReturnStatusRejected)
+}
+
+// Receive records that the returned goods arrived at the warehouse.
+func (uc *ReviewReturnUseCase) Receive(ctx context.Context, input ReviewReturnInput) (_ *entity.ReturnRequest, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewReturnUseCase.Receive")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ReturnsReceive); err != nil {
+		return nil, err
+	}
+	return uc.transition(ctx, input, entity.ReturnStatusReceived)
+}
+
+func (uc *ReviewReturnUseCase) transition(
+	ctx context.Context,
+	input ReviewReturnInput,
+	status entity.ReturnStatus,
+) (*entity.ReturnRequest, error) {
+	ret, err := uc.returnRepo.GetReturn(ctx, input.ReturnID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := ret.Status
+	if err := ret.TransitionTo(status); err != nil {
+		return nil, err
+	}
+	if note := strings.TrimSpace(input.Note); note != "" {
+		ret.ResolutionNote = note
+	}
+
+	restock := false
+	if status == entity.ReturnStatusReceived {
+		receivedAt := time.Now()
+		ret.ReceivedAt = &receivedAt
+		restock = input.Restock
+	}
+
+	if err := uc.returnRepo.UpdateReturnStatus(ctx, ret, from, restock); err != nil {
+		logger.FromContext(ctx).Warn("Failed to update return",
+			zap.Error(err),
+			zap.Int64("return_id", ret.ID),
+			zap.String("status", string(status)),
+		)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Return updated",
+		zap.Int64("return_id", ret.ID),
+		zap.Int64("order_id", ret.OrderID),
+		zap.String("status", string(ret.Status)),
+		zap.Bool("restocked", restock),
+	)
+
+	return ret, nil
+}
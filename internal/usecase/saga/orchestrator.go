@@ -0,0 +1,270 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const DefaultTimeout = 5 * time.Minute
+
+var ErrUnknownSaga = errors.New("unknown saga")
+
+// Step is one step of a saga. Actions and compensations may run more than
+// once after a crash, so they must be idempotent. When an action fails its
+// own compensation runs too, so compensations must also cope with an action
+// that only partly happened or never happened.
+type Step[T any] struct {
+	Name       string
+	Timeout    time.Duration
+	Action     func(ctx context.Context, data *T) error
+	Compensate func(ctx context.Context, data *T) error
+}
+
+// Definition describes a saga: its ordered steps and the time it may take
+// as a whole before it is compensated. T is the saga data, persisted as
+// JSON after every step.
+type Definition[T any] struct {
+	Name    string
+	Timeout time.Duration
+	Steps   []Step[T]
+}
+
+// Orchestrator runs sagas and persists their progress so unfinished sagas
+// can be resumed after a crash.
+type Orchestrator struct {
+	sagaRepo repository.SagaRepository
+
+	mu      sync.RWMutex
+	runners map[string]runner
+}
+
+// runner runs a saga of one definition without knowing its data type.
+type runner interface {
+	resume(ctx context.Context, saga *entity.Saga) error
+}
+
+func NewOrchestrator(sagaRepo repository.SagaRepository) *Orchestrator {
+	return &Orchestrator{
+		sagaRepo: sagaRepo,
+		runners:  make(map[string]runner),
+	}
+}
+
+// Register makes a definition available to Start and Resume.
+func Register[T any](o *Orchestrator, def Definition[T]) {
+	if def.Timeout == 0 {
+		def.Timeout = DefaultTimeout
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runners[def.Name] = &definitionRunner[T]{def: def, sagaRepo: o.sagaRepo}
+}
+
+// Start persists a new saga and runs it to the end. The run is detached from
+// ctx cancellation so a client going away cannot leave the saga half done.
+// When a step fails the returned error wraps the step error, and the saga
+// reports whether compensation succeeded.
+func Start[T any](ctx context.Context, o *Orchestrator, name, key string, data *T) (*entity.Saga, error) {
+	o.mu.RLock()
+	r, ok := o.runners[name].(*definitionRunner[T])
+	o.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSaga, name)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	saga := &entity.Saga{
+		Name:      name,
+		Key:       key,
+		Status:    entity.SagaStatusRunning,
+		Data:      payload,
+		Deadline:  now.Add(r.def.Timeout),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := o.sagaRepo.CreateSaga(ctx, saga); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Saga started",
+		zap.Int64("saga_id", saga.ID),
+		zap.String("saga", name),
+		zap.String("key", key),
+	)
+
+	return saga, r.run(context.WithoutCancel(ctx), saga, data)
+}
+
+// Resume continues the sagas that were interrupted, e.g. by a crash. Sagas
+// saved less than grace ago are assumed to still be running elsewhere.
+func (o *Orchestrator) Resume(ctx context.Context, grace time.Duration) error {
+	sagas, err := o.sagaRepo.ListUnfinishedSagas(ctx, time.Now().Add(-grace))
+	if err != nil {
+		return err
+	}
+
+	for i := range sagas {
+		saga := &sagas[i]
+
+		o.mu.RLock()
+		r, ok := o.runners[saga.Name]
+		o.mu.RUnlock()
+		if !ok {
+			logger.FromContext(ctx).Error("Cannot resume saga without definition",
+				zap.Int64("saga_id", saga.ID),
+				zap.String("saga", saga.Name),
+			)
+			continue
+		}
+
+		logger.FromContext(ctx).Info("Resuming saga",
+			zap.Int64("saga_id", saga.ID),
+			zap.String("saga", saga.Name),
+			zap.String("status", string(saga.Status)),
+			zap.Int("step", saga.Step),
+		)
+		if err := r.resume(ctx, saga); err != nil {
+			logger.FromContext(ctx).Warn("Resumed saga did not complete",
+				zap.Error(err),
+				zap.Int64("saga_id", saga.ID),
+				zap.String("status", string(saga.Status)),
+			)
+		}
+	}
+
+	return nil
+}
+
+type definitionRunner[T any] struct {
+	def      Definition[T]
+	sagaRepo repository.SagaRepository
+}
+
+func (r *definitionRunner[T]) resume(ctx context.Context, saga *entity.Saga) error {
+	data := new(T)
+	if err := json.Unmarshal(saga.Data, data); err != nil {
+		return fmt.Errorf("failed to decode saga data: %w", err)
+	}
+	return r.run(ctx, saga, data)
+}
+
+// run executes the remaining steps and, after a failure or timeout,
+// compensates the steps already executed in reverse order.
+func (r *definitionRunner[T]) run(ctx context.Context, saga *entity.Saga, data *T) error {
+	steps := r.def.Steps
+
+	// The steps log with the saga they run in
+	ctx = logger.WithContext(ctx, zap.Int64("saga_id", saga.ID), zap.String("saga", saga.Name))
+
+	var cause error
+	for saga.Status == entity.SagaStatusRunning {
+		if saga.Step >= len(steps) {
+			saga.Status = entity.SagaStatusCompleted
+			break
+		}
+
+		step := steps[saga.Step]
+		if time.Now().After(saga.Deadline) {
+			cause = fmt.Errorf("%w before step %s", entity.ErrSagaTimeout, step.Name)
+			r.startCompensation(ctx, saga, cause)
+			break
+		}
+
+		err := call(ctx, step.Timeout, step.Action, data)
+		if encodeErr := r.encode(saga, data); encodeErr != nil {
+			return encodeErr
+		}
+		if err != nil {
+			cause = fmt.Errorf("step %s: %w", step.Name, err)
+			// Include the failed step, it may have partly happened
+			saga.Step++
+			r.startCompensation(ctx, saga, cause)
+			break
+		}
+
+		saga.Step++
+		if err := r.sagaRepo.SaveSaga(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	for saga.Status == entity.SagaStatusCompensating {
+		if saga.Step == 0 {
+			saga.Status = entity.SagaStatusCompensated
+			break
+		}
+
+		step := steps[saga.Step-1]
+		if step.Compensate != nil {
+			err := call(ctx, step.Timeout, step.Compensate, data)
+			if encodeErr := r.encode(saga, data); encodeErr != nil {
+				return encodeErr
+			}
+			if err != nil {
+				saga.Status = entity.SagaStatusFailed
+				saga.Error = fmt.Sprintf("%s; compensating %s: %v", saga.Error, step.Name, err)
+				logger.FromContext(ctx).Error("Saga compensation failed",
+					zap.Error(err),
+					zap.String("step", step.Name),
+				)
+				break
+			}
+		}
+
+		saga.Step--
+		if err := r.sagaRepo.SaveSaga(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	if err := r.sagaRepo.SaveSaga(ctx, saga); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Saga finished",
+		zap.String("status", string(saga.Status)),
+	)
+
+	if cause == nil && saga.Status != entity.SagaStatusCompleted {
+		cause = errors.New(saga.Error)
+	}
+	return cause
+}
+
+func (r *definitionRunner[T]) startCompensation(ctx context.Context, saga *entity.Saga, cause error) {
+	saga.Status = entity.SagaStatusCompensating
+	saga.Error = cause.Error()
+	logger.FromContext(ctx).Warn("Saga failed, compensating", zap.Error(cause))
+}
+
+func (r *definitionRunner[T]) encode(saga *entity.Saga, data *T) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga data: %w", err)
+	}
+	saga.Data = payload
+	return nil
+}
+
+func call[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context, data *T) error, data *T) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return fn(ctx, data)
+}
@@ -0,0 +1,123 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/entity"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/repository"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/domain/service"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/authz"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/logger"
+	"github.com/Sinet2000/Martix-Orders-Go/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"strings"
+)
+
+var (
+	ErrEmptyCart       = errors.New("cart must contain at least one item")
+	ErrInvalidQuantity = errors.New("item quantity must be greater than zero")
+	ErrMissingCountry  = errors.New("destination country is required")
+	ErrProductNotFound = errors.New("one or more products not found")
+)
+
+type CartItem struct {
+	ProductID int64
+	Quantity  int
+}
+
+type QuoteShippingInput struct {
+	Country    string
+	Region     string
+	PostalCode string
+	Items      []CartItem
+}
+
+type QuoteShippingUseCase struct {
+	productRepo      repository.ProductRepository
+	shippingProvider service.ShippingRateProvider
+}
+
+func NewQuoteShippingUseCase(
+	productRepo repository.ProductRepository,
+	shippingProvider service.ShippingRateProvider,
+) *QuoteShippingUseCase {
+	return &QuoteShippingUseCase{
+		productRepo:      productRepo,
+		shippingProvider: shippingProvider,
+	}
+}
+
+// Execute quotes every shipping method available for the cart, cheapest
+// first. Item values are taken from the current product prices.
+func (uc *QuoteShippingUseCase) Execute(ctx context.Context, input QuoteShippingInput) (_ []entity.ShippingQuote, err error) {
+	ctx, span := tracing.Start(ctx, "QuoteShippingUseCase.Execute")
+	defer tracing.End(span, &err)
+
+	if err := authz.Require(ctx, authz.ShippingQuote); err != nil {
+		return nil, err
+	}
+
+	if len(input.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(input.Country))
+	if country == "" {
+		return nil, ErrMissingCountry
+	}
+
+	productIDs := make([]int64, 0, len(input.Items))
+	for _, item := range input.Items {
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	found, err := uc.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]entity.Product, len(found))
+	for _, product := range found {
+		products[product.ID] = product
+	}
+
+	req := service.ShippingQuoteRequest{
+		Destination: entity.Address{
+			Country:    country,
+			Region:     strings.TrimSpace(input.Region),
+			PostalCode: strings.ToUpper(strings.TrimSpace(input.PostalCode)),
+		},
+		Items: make([]service.ShippingItem, 0, len(input.Items)),
+	}
+	for _, item := range input.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, ErrProductNotFound
+		}
+		req.Items = append(req.Items, service.ShippingItem{
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+			WeightGrams: product.WeightGrams,
+			Value:       product.Price * float64(item.Quantity),
+		})
+	}
+
+	quotes, err := uc.shippingProvider.Quote(ctx, req)
+	if err != nil {
+		if !errors.Is(err, entity.ErrNoShippingAvailable) {
+			logger.FromContext(ctx).Error("Failed to quote shipping", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Debug("Shipping quoted",
+		zap.String("country", country),
+		zap.Int("weight_grams", req.TotalWeightGrams()),
+		zap.Int("quotes", len(quotes)),
+	)
+
+	return quotes, nil
+}
@@ -0,0 +1,58 @@
+-- Full-text search over orders.
+--
+-- The search document combines the shipping address, the names of the ordered
+-- products and free text notes. Because it depends on order_items and products
+-- it cannot be a GENERATED column, so it is kept up to date by triggers.
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+CREATE OR REPLACE FUNCTION orders_search_vector_update() RETURNS trigger AS
+$$
+BEGIN
+    NEW.search_vector :=
+            setweight(to_tsvector('simple', coalesce(NEW.shipping_addr, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce((SELECT string_agg(p.name, ' ')
+                                                      FROM order_items oi
+                                                               JOIN products p ON p.id = oi.product_id
+                                                      WHERE oi.order_id = NEW.id), '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(NEW.notes, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_orders_search_vector ON orders;
+CREATE TRIGGER trg_orders_search_vector
+    BEFORE INSERT OR UPDATE
+    ON orders
+    FOR EACH ROW
+EXECUTE FUNCTION orders_search_vector_update();
+
+-- Touching the parent order re-runs trg_orders_search_vector so that product
+-- names are picked up when items are added or removed.
+CREATE OR REPLACE FUNCTION order_items_refresh_order_search() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE orders SET search_vector = search_vector WHERE id = OLD.order_id;
+    ELSE
+        UPDATE orders SET search_vector = search_vector WHERE id = NEW.order_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_order_items_search_vector ON order_items;
+CREATE TRIGGER trg_order_items_search_vector
+    AFTER INSERT OR UPDATE OR DELETE
+    ON order_items
+    FOR EACH ROW
+EXECUTE FUNCTION order_items_refresh_order_search();
+
+CREATE INDEX IF NOT EXISTS idx_order_search ON orders USING GIN (search_vector);
+
+-- Backfill existing orders
+UPDATE orders SET search_vector = search_vector;
@@ -0,0 +1,11 @@
+-- Structured shipping and billing addresses.
+--
+-- shipping_addr is kept and now holds the single line rendering of
+-- shipping_address. It feeds full-text search and stays readable by clients
+-- that have not moved to the structured fields yet.
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS billing_address JSONB;
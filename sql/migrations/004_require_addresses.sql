@@ -0,0 +1,7 @@
+-- Every order has structured addresses once 003 has backfilled them.
+
+ALTER TABLE orders
+    ALTER COLUMN shipping_address SET NOT NULL;
+
+ALTER TABLE orders
+    ALTER COLUMN billing_address SET NOT NULL;
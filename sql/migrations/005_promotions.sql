@@ -0,0 +1,59 @@
+-- Coupons and the discount lines they produce on orders.
+
+CREATE TABLE IF NOT EXISTS coupons
+(
+    id                SERIAL PRIMARY KEY,
+    code              VARCHAR(50)    NOT NULL UNIQUE,
+    description       TEXT           NOT NULL DEFAULT '',
+    type              VARCHAR(20)    NOT NULL CHECK (type IN ('percentage', 'fixed_amount', 'free_shipping')),
+    value             DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (value >= 0),
+    min_order_value   DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (min_order_value >= 0),
+    valid_from        TIMESTAMP,
+    valid_until       TIMESTAMP,
+    max_uses          INT CHECK (max_uses > 0),
+    max_uses_per_user INT CHECK (max_uses_per_user > 0),
+    times_used        INT            NOT NULL DEFAULT 0 CHECK (times_used >= 0),
+    active            BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS coupon_redemptions
+(
+    id          SERIAL PRIMARY KEY,
+    coupon_id   INT NOT NULL,
+    user_id     INT NOT NULL,
+    order_id    INT NOT NULL,
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (coupon_id) REFERENCES coupons (id) ON DELETE RESTRICT,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS order_discounts
+(
+    id          SERIAL PRIMARY KEY,
+    order_id    INT            NOT NULL,
+    coupon_id   INT,
+    code        VARCHAR(50)    NOT NULL,
+    type        VARCHAR(20)    NOT NULL,
+    description TEXT           NOT NULL DEFAULT '',
+    amount      DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    FOREIGN KEY (coupon_id) REFERENCES coupons (id) ON DELETE SET NULL
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS subtotal DECIMAL(10, 2);
+
+UPDATE orders
+SET subtotal = total_amount
+WHERE subtotal IS NULL;
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal SET NOT NULL;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_user ON coupon_redemptions (coupon_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order ON order_discounts (order_id);
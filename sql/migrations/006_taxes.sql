@@ -0,0 +1,13 @@
+-- Per-line taxes and the order tax total.
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0);
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
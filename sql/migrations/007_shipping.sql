@@ -0,0 +1,10 @@
+-- Product weights for shipping rates and the shipping chosen for an order.
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(50) NOT NULL DEFAULT '';
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS shipping_cost DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (shipping_cost >= 0);
@@ -0,0 +1,35 @@
+-- Shipments for partial fulfillment and the partially_shipped order status.
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_status_check;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_status_check
+        CHECK (status IN ('pending', 'paid', 'partially_shipped', 'shipped', 'delivered', 'cancelled'));
+
+CREATE TABLE IF NOT EXISTS shipments
+(
+    id              SERIAL PRIMARY KEY,
+    order_id        INT          NOT NULL,
+    carrier         VARCHAR(100) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    status          VARCHAR(20)  NOT NULL CHECK (status IN ('shipped', 'delivered')),
+    shipped_at      TIMESTAMP    NOT NULL,
+    delivered_at    TIMESTAMP,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS shipment_items
+(
+    id            SERIAL PRIMARY KEY,
+    shipment_id   INT NOT NULL,
+    order_item_id INT NOT NULL,
+    quantity      INT NOT NULL CHECK (quantity > 0),
+    FOREIGN KEY (shipment_id) REFERENCES shipments (id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_order ON shipments (order_id);
+CREATE INDEX IF NOT EXISTS idx_shipments_tracking ON shipments (tracking_number);
+CREATE INDEX IF NOT EXISTS idx_shipment_items_shipment ON shipment_items (shipment_id);
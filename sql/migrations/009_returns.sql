@@ -0,0 +1,50 @@
+-- Return requests (RMA) for delivered orders and refunds recorded against orders.
+
+CREATE TABLE IF NOT EXISTS returns
+(
+    id              SERIAL PRIMARY KEY,
+    order_id        INT         NOT NULL,
+    user_id         INT         NOT NULL,
+    status          VARCHAR(20) NOT NULL CHECK (status IN ('requested', 'approved', 'rejected', 'received')),
+    comment         TEXT        NOT NULL DEFAULT '',
+    resolution_note TEXT        NOT NULL DEFAULT '',
+    restocked       BOOLEAN     NOT NULL DEFAULT FALSE,
+    received_at     TIMESTAMP,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS return_items
+(
+    id            SERIAL PRIMARY KEY,
+    return_id     INT         NOT NULL,
+    order_item_id INT         NOT NULL,
+    quantity      INT         NOT NULL CHECK (quantity > 0),
+    reason        VARCHAR(30) NOT NULL
+        CHECK (reason IN ('damaged', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other')),
+    FOREIGN KEY (return_id) REFERENCES returns (id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS refunds
+(
+    id              SERIAL PRIMARY KEY,
+    order_id        INT            NOT NULL,
+    return_id       INT,
+    amount          DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    shipping_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (shipping_amount >= 0),
+    reason          TEXT           NOT NULL DEFAULT '',
+    status          VARCHAR(20)    NOT NULL CHECK (status IN ('pending', 'completed', 'failed')),
+    reference       VARCHAR(100)   NOT NULL DEFAULT '',
+    created_at      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at    TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    FOREIGN KEY (return_id) REFERENCES returns (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_returns_order ON returns (order_id);
+CREATE INDEX IF NOT EXISTS idx_returns_status ON returns (status);
+CREATE INDEX IF NOT EXISTS idx_return_items_return ON return_items (return_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds (order_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds (status);
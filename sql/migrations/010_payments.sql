@@ -0,0 +1,25 @@
+-- Payments taken through a payment gateway.
+
+CREATE TABLE IF NOT EXISTS payments
+(
+    id               SERIAL PRIMARY KEY,
+    order_id         INT            NOT NULL,
+    amount           DECIMAL(10, 2) NOT NULL CHECK (amount >= 0),
+    provider         VARCHAR(50)    NOT NULL,
+    payment_method   VARCHAR(100)   NOT NULL,
+    status           VARCHAR(20)    NOT NULL
+        CHECK (status IN ('pending', 'authorized', 'captured', 'voided', 'declined', 'failed', 'refunded')),
+    authorization_id VARCHAR(100)   NOT NULL DEFAULT '',
+    capture_id       VARCHAR(100)   NOT NULL DEFAULT '',
+    failure_reason   TEXT           NOT NULL DEFAULT '',
+    created_at       TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order ON payments (order_id);
+CREATE INDEX IF NOT EXISTS idx_payments_status ON payments (status);
+
+-- At most one payment per order may be running or successful
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_active_order ON payments (order_id)
+    WHERE status IN ('pending', 'authorized', 'captured');
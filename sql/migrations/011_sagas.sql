@@ -0,0 +1,31 @@
+-- Persisted saga state for multi-step operations such as checkout, and the
+-- stock reserved for orders by the checkout saga.
+
+CREATE TABLE IF NOT EXISTS sagas
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    key        VARCHAR(100) NOT NULL,
+    status     VARCHAR(20)  NOT NULL
+        CHECK (status IN ('running', 'compensating', 'completed', 'compensated', 'failed')),
+    step       INT          NOT NULL DEFAULT 0,
+    data       JSONB        NOT NULL DEFAULT '{}',
+    error      TEXT         NOT NULL DEFAULT '',
+    deadline   TIMESTAMP    NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one unfinished saga per name and key, e.g. one checkout per order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sagas_active_key ON sagas (name, key)
+    WHERE status IN ('running', 'compensating');
+CREATE INDEX IF NOT EXISTS idx_sagas_unfinished ON sagas (updated_at)
+    WHERE status IN ('running', 'compensating');
+
+CREATE TABLE IF NOT EXISTS stock_reservations
+(
+    order_id   INT PRIMARY KEY,
+    status     VARCHAR(20) NOT NULL CHECK (status IN ('reserved', 'released')),
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
@@ -0,0 +1,34 @@
+-- Event store for the order aggregate. The orders, order_items and
+-- order_discounts tables become projections of these events.
+
+CREATE TABLE IF NOT EXISTS order_events
+(
+    id           BIGSERIAL PRIMARY KEY,
+    aggregate_id INT         NOT NULL,
+    sequence     INT         NOT NULL CHECK (sequence > 0),
+    type         VARCHAR(50) NOT NULL,
+    data         JSONB       NOT NULL,
+    occurred_at  TIMESTAMP   NOT NULL,
+    recorded_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Optimistic concurrency: two writers cannot append the same sequence
+    UNIQUE (aggregate_id, sequence)
+);
+
+CREATE TABLE IF NOT EXISTS order_snapshots
+(
+    aggregate_id INT PRIMARY KEY,
+    sequence     INT       NOT NULL,
+    state        JSONB     NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS projection_checkpoints
+(
+    name       VARCHAR(100) PRIMARY KEY,
+    position   BIGINT       NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sequence number of the last event reflected in the row
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS event_sequence INT NOT NULL DEFAULT 0;
@@ -0,0 +1,60 @@
+-- Denormalized read models maintained asynchronously from order events.
+-- (Version 13 is the Go order event backfill.)
+
+CREATE TABLE IF NOT EXISTS user_order_summaries
+(
+    order_id        INT PRIMARY KEY,
+    user_id         INT            NOT NULL,
+    status          VARCHAR(20)    NOT NULL,
+    item_count      INT            NOT NULL,
+    line_count      INT            NOT NULL,
+    total_amount    DECIMAL(10, 2) NOT NULL,
+    shipping_method VARCHAR(50)    NOT NULL DEFAULT '',
+    created_at      TIMESTAMP      NOT NULL,
+    updated_at      TIMESTAMP      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_order_summaries_user ON user_order_summaries (user_id, created_at DESC);
+
+-- Per order state of the sales projection, needed to move an order between
+-- statuses in the daily totals
+CREATE TABLE IF NOT EXISTS sales_order_facts
+(
+    order_id INT         NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    day      DATE        NOT NULL,
+    status   VARCHAR(20) NOT NULL,
+    quantity INT         NOT NULL,
+    revenue  DECIMAL(12, 2) NOT NULL,
+    PRIMARY KEY (order_id, category)
+);
+
+CREATE TABLE IF NOT EXISTS sales_orders
+(
+    order_id   INT PRIMARY KEY,
+    day        DATE           NOT NULL,
+    status     VARCHAR(20)    NOT NULL,
+    item_count INT            NOT NULL,
+    revenue    DECIMAL(12, 2) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS daily_sales
+(
+    day         DATE           NOT NULL,
+    status      VARCHAR(20)    NOT NULL,
+    order_count INT            NOT NULL DEFAULT 0,
+    item_count  INT            NOT NULL DEFAULT 0,
+    revenue     DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, status)
+);
+
+CREATE TABLE IF NOT EXISTS daily_category_sales
+(
+    day         DATE           NOT NULL,
+    status      VARCHAR(20)    NOT NULL,
+    category    VARCHAR(100)   NOT NULL,
+    order_count INT            NOT NULL DEFAULT 0,
+    quantity    INT            NOT NULL DEFAULT 0,
+    revenue     DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, status, category)
+);
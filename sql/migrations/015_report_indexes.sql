@@ -0,0 +1,2 @@
+-- Sales reports aggregate orders by the time they were placed
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders (created_at);
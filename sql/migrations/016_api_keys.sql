@@ -0,0 +1,17 @@
+-- API keys of service-to-service callers. Only a SHA-256 hash of each key is
+-- stored; the prefix identifies the key without revealing it.
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    prefix        VARCHAR(20)  NOT NULL UNIQUE,
+    key_hash      CHAR(64)     NOT NULL,
+    scopes        TEXT[]       NOT NULL DEFAULT '{}',
+    expires_at    TIMESTAMP,
+    last_used_at  TIMESTAMP,
+    revoked_at    TIMESTAMP,
+    rotated_to_id INT REFERENCES api_keys (id),
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- A return is refunded at most once; a failed refund may be retried
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_return ON refunds (return_id) WHERE status <> 'failed';
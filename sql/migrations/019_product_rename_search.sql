@@ -0,0 +1,23 @@
+-- The search document of an order holds the names of its products, so a
+-- renamed product must refresh the orders containing it. Touching them
+-- re-runs trg_orders_search_vector.
+CREATE OR REPLACE FUNCTION products_refresh_order_search() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE orders
+    SET search_vector = search_vector
+    WHERE id IN (SELECT order_id FROM order_items WHERE product_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_products_search_vector ON products;
+CREATE TRIGGER trg_products_search_vector
+    AFTER UPDATE OF name
+    ON products
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION products_refresh_order_search();
+
+-- Pick up the renames made so far
+UPDATE orders SET search_vector = search_vector;
@@ -0,0 +1,8 @@
+-- A payment whose authorization the gateway would not void is kept apart
+-- from the active ones, so it no longer blocks paying the order again.
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments
+    ADD CONSTRAINT payments_status_check
+        CHECK (status IN ('pending', 'authorized', 'captured', 'voided', 'void_failed', 'declined', 'failed',
+                          'refunded'));
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations applied on top of
+// sql/orders_create.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS